@@ -0,0 +1,211 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var appendTokenRe = regexp.MustCompile(`^((\w+[\_]?[\-]?)+)\[[\+\-]\]$`)
+
+// InsertAt inserts a value into an array at index shifting the following elements.
+// Index may be equal to the array length, which appends the value.
+// A missing array is created when index is 0.
+//
+//	err := InsertAt(document, "one.two.three", 1, "value")
+//	err := InsertAt(document, "one/two/three", 1, "value", "/")
+func InsertAt(original_data map[string]interface{}, path string, index int, value interface{}, separator_arr ...string) (err error) {
+	separator := getSeparator(separator_arr)
+
+	items, err := getArray(original_data, path, separator)
+	if err != nil {
+		return
+	}
+	if index < 0 || index > len(items) {
+		return fmt.Errorf(
+			"%s: Min index is 0, Max index is %d. You passed index %d", path, len(items), index,
+		)
+	}
+
+	slices := make([]interface{}, 0, len(items)+1)
+	slices = append(slices, items[:index]...)
+	slices = append(slices, value)
+	slices = append(slices, items[index:]...)
+
+	return UpdateProperty(original_data, path, slices, separator)
+}
+
+// Append adds values to the end of an array. The array is created if it does not exist.
+// Paths use the default separator, see AppendSep.
+//
+//	err := Append(document, "one.two.three", 4, 5)
+func Append(original_data map[string]interface{}, path string, values ...interface{}) error {
+	return AppendSep(original_data, path, values, getSeparator(nil))
+}
+
+// AppendSep is Append with a separator.
+//
+//	err := AppendSep(document, "one/two/three", []interface{}{4, 5}, "/")
+func AppendSep(original_data map[string]interface{}, path string, values []interface{}, separator string) (err error) {
+	separator = getSeparator([]string{separator})
+
+	items, err := getArray(original_data, path, separator)
+	if err != nil {
+		return
+	}
+
+	slices := make([]interface{}, 0, len(items)+len(values))
+	slices = append(slices, items...)
+	slices = append(slices, values...)
+
+	return UpdateProperty(original_data, path, slices, separator)
+}
+
+// Prepend adds values to the beginning of an array. The array is created if it does not exist.
+// Paths use the default separator, see PrependSep.
+//
+//	err := Prepend(document, "one.two.three", -1, 0)
+func Prepend(original_data map[string]interface{}, path string, values ...interface{}) error {
+	return PrependSep(original_data, path, values, getSeparator(nil))
+}
+
+// PrependSep is Prepend with a separator.
+//
+//	err := PrependSep(document, "one/two/three", []interface{}{-1, 0}, "/")
+func PrependSep(original_data map[string]interface{}, path string, values []interface{}, separator string) (err error) {
+	separator = getSeparator([]string{separator})
+
+	items, err := getArray(original_data, path, separator)
+	if err != nil {
+		return
+	}
+
+	slices := make([]interface{}, 0, len(items)+len(values))
+	slices = append(slices, values...)
+	slices = append(slices, items...)
+
+	return UpdateProperty(original_data, path, slices, separator)
+}
+
+// RemoveWhere removes every array element the predicate returns true for.
+// Returns the number of removed elements.
+//
+//	removed, err := RemoveWhere(document, "one.two.three", func(v interface{}) bool {
+//		return v == 2
+//	})
+func RemoveWhere(original_data map[string]interface{}, path string, predicate func(value interface{}) bool, separator_arr ...string) (removed int, err error) {
+	separator := getSeparator(separator_arr)
+
+	value, err := GetProperty(original_data, path, separator)
+	if err != nil {
+		return
+	}
+	if !isKind(value, reflect.Slice) {
+		err = fmt.Errorf(
+			"%s: is not an array", path,
+		)
+		return
+	}
+
+	items := toSlice(value)
+	slices := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if predicate(item) {
+			removed++
+		} else {
+			slices = append(slices, item)
+		}
+	}
+	if removed == 0 {
+		return
+	}
+
+	err = UpdateProperty(original_data, path, slices, separator)
+	return
+}
+
+// getArray returns a copy of the array at path as []interface{}.
+// A missing property is treated as an empty array.
+func getArray(original_data map[string]interface{}, path string, separator string) ([]interface{}, error) {
+	value, err := GetProperty(original_data, path, separator)
+	if err != nil {
+		if err = missingProperty(original_data, path, separator); err != nil {
+			return nil, err
+		}
+		return []interface{}{}, nil
+	}
+	if !isKind(value, reflect.Slice) {
+		return nil, fmt.Errorf(
+			"%s: is not an array", path,
+		)
+	}
+	return toSlice(value), nil
+}
+
+// missingProperty explains why a property can not be read. It returns nil
+// when a key on the path does not exist, so the property can be created,
+// and an error when a level exists but can not hold the rest of the path.
+func missingProperty(original_data map[string]interface{}, path string, separator string) error {
+	var parent interface{} = original_data
+	levels := splitLevels(path, separator)
+	for i, level := range levels {
+		property := level
+		if indexed_property, _, indexed := parseIndexLevel(level); indexed {
+			property = indexed_property
+		}
+		if _, ok := lookupKey(parent, property); !ok {
+			return nil
+		}
+
+		prefix := strings.Join(levels[:i+1], separator)
+		value, err := getProperty(original_data, prefix, separator)
+		if err != nil {
+			return err
+		}
+		if i < len(levels)-1 && !isObject(value) {
+			return fmt.Errorf(
+				"%s: is not an object", prefix,
+			)
+		}
+		parent = value
+	}
+	return nil
+}
+
+// toSlice copies any slice into a []interface{}.
+func toSlice(value interface{}) []interface{} {
+	slice := reflect.ValueOf(value)
+	slices := make([]interface{}, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		slices[i] = slice.Index(i).Interface()
+	}
+	return slices
+}
+
+// resolveAppendTokens replaces `property[+]` and `property[-]` levels
+// with the index right after the last element of the array.
+func resolveAppendTokens(original_data interface{}, path string, separator string) string {
+	if !strings.Contains(path, "[+]") && !strings.Contains(path, "[-]") {
+		return path
+	}
+
+	levels := splitLevels(path, separator)
+	for i, level := range levels {
+		matched := appendTokenRe.FindStringSubmatch(level)
+		if matched == nil {
+			continue
+		}
+		property := matched[1]
+
+		length := 0
+		prefix := append(levels[:i:i], property)
+		if value, err := getProperty(original_data, strings.Join(prefix, separator), separator); err == nil {
+			if isKind(value, reflect.Slice) {
+				length = reflect.ValueOf(value).Len()
+			}
+		}
+		levels[i] = fmt.Sprintf("%s[%d]", property, length)
+	}
+	return strings.Join(levels, separator)
+}
@@ -0,0 +1,242 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestInsertAt(t *testing.T) {
+	cases := []struct {
+		in    map[string]interface{}
+		path  string
+		index int
+		value interface{}
+		out   interface{}
+		err   error
+	}{
+		{
+			in:    setupDocument(),
+			path:  "one.two.three",
+			index: 1,
+			value: "inserted",
+			out:   []interface{}{1, "inserted", 2, 3},
+			err:   nil,
+		},
+		{
+			in:    setupDocument(),
+			path:  "one.two.three",
+			index: 0,
+			value: "inserted",
+			out:   []interface{}{"inserted", 1, 2, 3},
+			err:   nil,
+		},
+		{
+			in:    setupDocument(),
+			path:  "one.two.three",
+			index: 3,
+			value: "inserted",
+			out:   []interface{}{1, 2, 3, "inserted"},
+			err:   nil,
+		},
+		{
+			in:    setupDocument(),
+			path:  "one.two.three",
+			index: 5,
+			value: "inserted",
+			out:   []int{1, 2, 3},
+			err:   fmt.Errorf("one.two.three: Min index is 0, Max index is 3. You passed index 5"),
+		},
+		{
+			in:    setupDocument(),
+			path:  "one.two",
+			index: 0,
+			value: "inserted",
+			out: map[string]interface{}{
+				"three": []int{1, 2, 3},
+			},
+			err: fmt.Errorf("one.two: is not an array"),
+		},
+		{
+			in:    setupDocument(),
+			path:  "one.two.missing",
+			index: 0,
+			value: "inserted",
+			out:   []interface{}{"inserted"},
+			err:   nil,
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		err_case := InsertAt(c.in, c.path, c.index, c.value)
+		if !reflect.DeepEqual(c.err, err_case) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err_case, c.err)
+		}
+		out, _ := GetProperty(c.in, c.path)
+		if !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+}
+
+func TestInsertAtDoesNotModifyOriginalSlice(t *testing.T) {
+	items := []interface{}{1, 2, 3}
+	doc := map[string]interface{}{
+		"items": items,
+	}
+
+	if err := InsertAt(doc, "items", 1, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(items, []interface{}{1, 2, 3}) {
+		t.Error("Original slice should not change. Got ", items)
+	}
+}
+
+func TestAppendPrepend(t *testing.T) {
+	doc := setupDocument()
+
+	if err := Append(doc, "one.two.three", 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := Prepend(doc, "one.two.three", -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrependSep(doc, "one/two/three", []interface{}{-2}, "/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendSep(doc, "one::two::three", []interface{}{6, 7}, "::"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := GetProperty(doc, "one.two.three")
+	if !reflect.DeepEqual(out, []interface{}{-2, -1, 1, 2, 3, 4, 5, 6, 7}) {
+		t.Error("Should be [-2 -1 1 2 3 4 5 6 7]. Got ", out)
+	}
+
+	values := []interface{}{"x", "y"}
+	if err := Append(doc, "spread", values...); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = GetProperty(doc, "spread")
+	if !reflect.DeepEqual(out, values) {
+		t.Error("Should be [x y]. Got ", out)
+	}
+
+	if err := Append(doc, "new.list", "a"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = GetProperty(doc, "new.list")
+	if !reflect.DeepEqual(out, []interface{}{"a"}) {
+		t.Error("Should be [a]. Got ", out)
+	}
+
+	err := Append(doc, "one.two", "a")
+	if !reflect.DeepEqual(err, fmt.Errorf("one.two: is not an array")) {
+		t.Error("Should fail on non array. Got ", err)
+	}
+
+	doc["name"] = "text"
+	err = Prepend(doc, "name.list", "a")
+	if !reflect.DeepEqual(err, fmt.Errorf("name: is not an object")) {
+		t.Error("Should fail under a non object. Got ", err)
+	}
+	err = Append(doc, "one.four.five[7].list", "a")
+	if !reflect.DeepEqual(err, fmt.Errorf("five: Min index is 0, Max index is 3. You passed index 7")) {
+		t.Error("Should fail on a missing element. Got ", err)
+	}
+	if _, ok := doc["name.list"]; ok {
+		t.Error("Should not create a key named after the path")
+	}
+}
+
+func TestRemoveWhere(t *testing.T) {
+	doc := setupDocument()
+
+	removed, err := RemoveWhere(doc, "one.four.five", func(v interface{}) bool {
+		return v.(int) > 15
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Should remove 2 elements. Removed %d", removed)
+	}
+	out, _ := GetProperty(doc, "one.four.five")
+	if !reflect.DeepEqual(out, []interface{}{11}) {
+		t.Error("Should be [11]. Got ", out)
+	}
+
+	removed, err = RemoveWhere(doc, "one/two/three", func(v interface{}) bool {
+		return false
+	}, "/")
+	if err != nil || removed != 0 {
+		t.Errorf("Nothing should be removed. Got %d, %v", removed, err)
+	}
+	out, _ = GetProperty(doc, "one.two.three")
+	if !reflect.DeepEqual(out, []int{1, 2, 3}) {
+		t.Error("Untouched array should keep its type. Got ", out)
+	}
+
+	_, err = RemoveWhere(doc, "one.two", func(v interface{}) bool { return true })
+	if !reflect.DeepEqual(err, fmt.Errorf("one.two: is not an array")) {
+		t.Error("Should fail on non array. Got ", err)
+	}
+
+	_, err = RemoveWhere(doc, "one.missing", func(v interface{}) bool { return true })
+	if !reflect.DeepEqual(err, fmt.Errorf("Property missing does not exist")) {
+		t.Error("Should fail on missing property. Got ", err)
+	}
+}
+
+func TestAppendToken(t *testing.T) {
+	cases := []MapTest{
+		{
+			in:        setupDocument(),
+			path:      "one.two.three[+]",
+			value:     4,
+			separator: ".",
+			out:       []interface{}{1, 2, 3, 4},
+		},
+		{
+			in:        setupDocument(),
+			path:      "one/two/three[-]",
+			value:     4,
+			separator: "/",
+			out:       []interface{}{1, 2, 3, 4},
+		},
+		{
+			in:        setupDocument(),
+			path:      "one.two.list[+]",
+			value:     "first",
+			separator: ".",
+			out:       []interface{}{"first"},
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		err_case := UpdateProperty(c.in, c.path, c.value, c.separator)
+		if !reflect.DeepEqual(c.err, err_case) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err_case, c.err)
+		}
+		out, _ := GetProperty(c.in, c.path[:len(c.path)-3], c.separator)
+		if !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+
+	doc := map[string]interface{}{}
+	CreateProperty(doc, "users[+].name", "first")
+	CreateProperty(doc, "users[+].name", "second")
+	if !reflect.DeepEqual(doc["users"], []interface{}{
+		map[string]interface{}{"name": "first"},
+		map[string]interface{}{"name": "second"},
+	}) {
+		t.Error("Should be [{name:first}, {name:second}]. Got ", doc["users"])
+	}
+}
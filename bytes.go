@@ -0,0 +1,129 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// GetBytes returns the raw JSON of a property without decoding the document.
+// Subtrees which are not on the path are skipped. The path grammar and
+// errors are the ones of GetProperty. The returned slice shares memory with data.
+// When an object has duplicate keys the last one is used, like json.Unmarshal does.
+//
+//	raw, err := GetBytes(payload, "repository.owner.login") // []byte(`"firewut"`)
+//	raw, err := GetBytes(payload, "commits[0]")             // []byte(`{"id": "a1"}`)
+func GetBytes(data []byte, path string, separator_arr ...string) ([]byte, error) {
+	start, end, err := findBytes(data, path, getSeparator(separator_arr))
+	if err != nil {
+		return nil, err
+	}
+	return data[start:end], nil
+}
+
+// GetBytesValue returns a property of a JSON document decoded the way
+// json.Unmarshal decodes into an interface{}. See GetBytes.
+//
+//	login, err := GetBytesValue(payload, "repository.owner.login") // "firewut"
+func GetBytesValue(data []byte, path string, separator_arr ...string) (interface{}, error) {
+	raw, err := GetBytes(data, path, separator_arr...)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// findBytes returns the position of the value at path in a JSON document.
+func findBytes(data []byte, path string, separator string) (int, int, error) {
+	s := &scanner{data: data}
+	s.skipSpace()
+	begin := s.pos
+
+	if len(path) == 0 || path == separator {
+		if err := s.skipValue(); err != nil {
+			return 0, 0, err
+		}
+		return begin, s.pos, nil
+	}
+
+	level, next := nextLevel(path, separator, 0)
+	if len(level) == 0 || s.peek() != '{' {
+		return 0, 0, fmt.Errorf("Property %s does not exist", path)
+	}
+
+	start := 0
+	for {
+		following, after := nextLevel(path, separator, next)
+
+		property := level
+		var ok bool
+		var err error
+
+		// Levels like `property[index]` select an element of an array
+		if indexed_property, index_found, indexed := parseIndexLevel(level); !indexed {
+			if ok, err = s.findKey(property); err != nil {
+				return 0, 0, err
+			}
+		} else {
+			property = indexed_property
+
+			index, err := strconv.Atoi(index_found)
+			if err != nil {
+				return 0, 0, fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", property, index_found),
+					"number",
+				)
+			}
+			if ok, err = s.findKey(property); err != nil {
+				return 0, 0, err
+			}
+			if !ok {
+				return 0, 0, fmt.Errorf(
+					"Property %s does not exist", property,
+				)
+			}
+			if s.peek() != '[' {
+				return 0, 0, fmt.Errorf(
+					"%s: is not an array", property,
+				)
+			}
+			found, length, err := s.findIndex(index)
+			if err != nil {
+				return 0, 0, err
+			}
+			if !found {
+				return 0, 0, fmt.Errorf(
+					"%s: Min index is 0, Max index is %d. You passed index %d", property, length, index,
+				)
+			}
+		}
+
+		if len(following) == 0 {
+			if !ok {
+				return 0, 0, fmt.Errorf("Property %s does not exist", remainingPath(path, separator, start))
+			}
+			begin := s.pos
+			if err := s.skipValue(); err != nil {
+				return 0, 0, err
+			}
+			return begin, s.pos, nil
+		}
+
+		if !ok {
+			return 0, 0, fmt.Errorf(
+				"Property %s does not exist", property,
+			)
+		}
+		if s.peek() != '{' {
+			return 0, 0, fmt.Errorf("Property %s does not exist", remainingPath(path, separator, start))
+		}
+
+		start = next
+		level, next = following, after
+	}
+}
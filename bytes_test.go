@@ -0,0 +1,154 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var testPayload = []byte(`{
+	"action": "opened",
+	"number": 42,
+	"draft": false,
+	"labels": null,
+	"pull_request": {
+		"title": "Fix \"quotes\" and ] brackets {",
+		"user": {"login": "firewut", "id": 1},
+		"commits": [
+			{"sha": "a1", "files": ["a.go", "b.go"]},
+			{"sha": "b2", "files": []}
+		]
+	},
+	"escaped.key": 1,
+	"key\/slash": "slash"
+}`)
+
+func TestGetBytes(t *testing.T) {
+	tests := []MapTest{
+		{path: "action", out: `"opened"`},
+		{path: "number", out: `42`},
+		{path: "draft", out: `false`},
+		{path: "labels", out: `null`},
+		{path: "pull_request.title", out: `"Fix \"quotes\" and ] brackets {"`},
+		{path: "pull_request.user", out: `{"login": "firewut", "id": 1}`},
+		{path: "pull_request.commits[1].sha", out: `"b2"`},
+		{path: "pull_request/commits[0]/files", separator: "/", out: `["a.go", "b.go"]`},
+		{path: "key/slash", separator: "|", out: `"slash"`},
+		{path: "missing", err: fmt.Errorf("Property missing does not exist")},
+		{path: "pull_request.missing.login", err: fmt.Errorf("Property missing does not exist")},
+		{path: "pull_request.user.name", err: fmt.Errorf("Property name does not exist")},
+		{path: "pull_request.title.length", err: fmt.Errorf("Property title.length does not exist")},
+		{path: "labels.name", err: fmt.Errorf("Property labels.name does not exist")},
+		{path: "pull_request.user[0]", err: fmt.Errorf("user: is not an array")},
+		{path: "pull_request.commits[2]", err: fmt.Errorf("commits: Min index is 0, Max index is 2. You passed index 2")},
+		{path: "pull_request.labels[0]", err: fmt.Errorf("Property labels does not exist")},
+	}
+
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(testPayload, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range tests {
+		raw, err := GetBytes(testPayload, test.path, test.separator)
+		_, property_err := GetProperty(document, test.path, test.separator)
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, err, test.err)
+			}
+			if property_err == nil || property_err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] GetProperty error should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, property_err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if string(raw) != test.out {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%s \n \n\t%s", i+1, len(tests), test.path, raw, test.out)
+		}
+	}
+}
+
+func TestGetBytesValue(t *testing.T) {
+	document := make(map[string]interface{})
+	json.Unmarshal(testPayload, &document)
+
+	tests := []MapTest{
+		{path: ""},
+		{path: "number"},
+		{path: "pull_request.user"},
+		{path: "pull_request.commits[0].files"},
+		{path: "escaped.key", separator: "|"},
+	}
+	for i, test := range tests {
+		value, err := GetBytesValue(testPayload, test.path, test.separator)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		expected, _ := GetProperty(document, test.path, test.separator)
+		if !reflect.DeepEqual(value, expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, value, expected)
+		}
+	}
+}
+
+func TestGetBytesInvalid(t *testing.T) {
+	tests := []struct {
+		data string
+		path string
+		err  string
+	}{
+		{data: `{"a": 1`, path: "b", err: "Invalid JSON: unexpected end of input"},
+		{data: `{"a" 1}`, path: "a", err: `Invalid JSON: unexpected '1' at offset 5`},
+		{data: `{"a": tru, "b": 1}`, path: "b", err: `Invalid JSON: unexpected 't' at offset 6`},
+		{data: `{"a": {"b": [1, 2}`, path: "c", err: "Invalid JSON: unexpected end of input"},
+		{data: `[1, 2]`, path: "a", err: "Property a does not exist"},
+	}
+	for i, test := range tests {
+		_, err := GetBytes([]byte(test.data), test.path)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.data, err, test.err)
+		}
+	}
+}
+
+func TestGetBytesDuplicateKeys(t *testing.T) {
+	data := []byte(`{"a": 1, "b": {"c": 1}, "a": 2, "b": {"c": 2, "c": 3}, "d": [{"e": 1, "e": 2}]}`)
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []MapTest{
+		{path: "a", out: `2`},
+		{path: "b", out: `{"c": 2, "c": 3}`},
+		{path: "b.c", out: `3`},
+		{path: "d[0].e", out: `2`},
+	}
+	for i, test := range tests {
+		raw, err := GetBytes(data, test.path)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if string(raw) != test.out {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%s \n \n\t%s", i+1, len(tests), test.path, raw, test.out)
+		}
+		value, _ := GetBytesValue(data, test.path)
+		expected, _ := GetProperty(document, test.path)
+		if !reflect.DeepEqual(value, expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal json.Unmarshal \n\t%v \n \n\t%v", i+1, len(tests), test.path, value, expected)
+		}
+	}
+}
+
+func BenchmarkGetBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetBytes(testPayload, "pull_request.commits[1].sha")
+	}
+}
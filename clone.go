@@ -0,0 +1,174 @@
+package gjm
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// CloneFunc returns a deep copy of a value of a registered type.
+// The returned value must have the same type as the passed one.
+type CloneFunc func(value interface{}) interface{}
+
+var (
+	clone_funcs_mu sync.RWMutex
+	clone_funcs    = map[reflect.Type]CloneFunc{
+		reflect.TypeOf(time.Time{}): func(value interface{}) interface{} {
+			return value
+		},
+	}
+)
+
+// RegisterCloneFunc registers a function used by Clone to copy values of type `t`.
+// Registered functions take precedence over the built-in reflect based copy.
+//
+//	RegisterCloneFunc(reflect.TypeOf(big.Int{}), func(v interface{}) interface{} {
+//		i := v.(big.Int)
+//		return *new(big.Int).Set(&i)
+//	})
+func RegisterCloneFunc(t reflect.Type, fn CloneFunc) {
+	clone_funcs_mu.Lock()
+	defer clone_funcs_mu.Unlock()
+
+	if fn == nil {
+		delete(clone_funcs, t)
+		return
+	}
+	clone_funcs[t] = fn
+}
+
+// Clone returns a deep copy of a document.
+// Maps, slices, arrays, pointers and exported struct fields are copied recursively,
+// typed containers keep their types. Cyclic references are reproduced in the copy.
+//
+//	copied := Clone(document)
+func Clone(original_data map[string]interface{}) map[string]interface{} {
+	if original_data == nil {
+		return nil
+	}
+	return newCloner().clone(reflect.ValueOf(original_data)).Interface().(map[string]interface{})
+}
+
+// CloneAt returns a deep copy of a property.
+//
+//	property, err := CloneAt(document, "one.two.three[0]")
+//	property, err := CloneAt(document, "one/two/three[0]", "/")
+func CloneAt(original_data map[string]interface{}, path string, separator_arr ...string) (interface{}, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return nil, err
+	}
+	return cloneValue(value), nil
+}
+
+// cloneValue returns a deep copy of any value.
+func cloneValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return newCloner().clone(reflect.ValueOf(value)).Interface()
+}
+
+type cloneKey struct {
+	t   reflect.Type
+	ptr uintptr
+	len int
+}
+
+type cloner struct {
+	seen map[cloneKey]reflect.Value
+}
+
+func newCloner() *cloner {
+	return &cloner{
+		seen: make(map[cloneKey]reflect.Value),
+	}
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	clone_funcs_mu.RLock()
+	fn, ok := clone_funcs[v.Type()]
+	clone_funcs_mu.RUnlock()
+	if ok {
+		if !v.CanInterface() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		if result := fn(v.Interface()); result != nil {
+			copied.Set(reflect.ValueOf(result))
+		}
+		return copied
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(c.clone(v.Elem()))
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := cloneKey{v.Type(), v.Pointer(), 0}
+		if copied, ok := c.seen[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.seen[key] = copied
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := cloneKey{v.Type(), v.Pointer(), v.Len()}
+		if copied, ok := c.seen[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		c.seen[key] = copied
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return copied
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := cloneKey{v.Type(), v.Pointer(), 0}
+		if copied, ok := c.seen[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		c.seen[key] = copied
+		copied.Elem().Set(c.clone(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(c.clone(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
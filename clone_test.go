@@ -0,0 +1,126 @@
+package gjm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cloneTestStruct struct {
+	Name   string
+	Tags   []string
+	hidden map[string]int
+}
+
+func TestClone(t *testing.T) {
+	documents := []map[string]interface{}{
+		setupDocument(),
+		setupDocument_I(),
+		setupDocument_II(),
+		setupDocument_III(),
+	}
+
+	for i, document := range documents {
+		copied := Clone(document)
+		if !reflect.DeepEqual(copied, document) {
+			t.Errorf("\n[%d: Results should equal] \n\t%v \n \n\t%v", i+1, copied, document)
+		}
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	document := setupDocument_II()
+	copied := Clone(document)
+
+	UpdateProperty(copied, "one[3].three[0].four.five", "changed")
+	copied["one"].([]map[string]interface{})[0]["two"].([]map[string]interface{})[0]["three"] = "changed"
+
+	if !reflect.DeepEqual(document, setupDocument_II()) {
+		t.Error("Original document should not change. Got ", document)
+	}
+
+	typed := map[string]interface{}{
+		"ints":   []int{1, 2, 3},
+		"array":  [2][]int{{1}, {2}},
+		"ptr":    &cloneTestStruct{Name: "a", Tags: []string{"x"}},
+		"time":   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"nil":    nil,
+		"nilmap": map[string]interface{}(nil),
+	}
+	copied = Clone(typed)
+	if !reflect.DeepEqual(copied, typed) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", copied, typed)
+	}
+
+	copied["ints"].([]int)[0] = 100
+	copied["array"].([2][]int)[0][0] = 100
+	copied["ptr"].(*cloneTestStruct).Tags[0] = "changed"
+	if typed["ints"].([]int)[0] != 1 {
+		t.Error("Typed slice should be copied")
+	}
+	if typed["array"].([2][]int)[0][0] != 1 {
+		t.Error("Slices inside arrays should be copied")
+	}
+	if typed["ptr"].(*cloneTestStruct).Tags[0] != "x" {
+		t.Error("Pointers should be copied")
+	}
+}
+
+func TestCloneCycles(t *testing.T) {
+	document := map[string]interface{}{
+		"name": "root",
+	}
+	document["self"] = document
+	shared := []interface{}{1, 2}
+	document["a"] = shared
+	document["b"] = shared
+
+	copied := Clone(document)
+
+	self := copied["self"].(map[string]interface{})
+	if reflect.ValueOf(self).Pointer() != reflect.ValueOf(copied).Pointer() {
+		t.Error("Cycle should point to the copied document")
+	}
+	if reflect.ValueOf(copied["a"]).Pointer() == reflect.ValueOf(shared).Pointer() {
+		t.Error("Shared slice should be copied")
+	}
+	if reflect.ValueOf(copied["a"]).Pointer() != reflect.ValueOf(copied["b"]).Pointer() {
+		t.Error("Shared slice should stay shared in the copy")
+	}
+}
+
+func TestCloneAt(t *testing.T) {
+	document := setupDocument()
+
+	property, err := CloneAt(document, "one/two", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	property.(map[string]interface{})["three"].([]int)[0] = 100
+	if !reflect.DeepEqual(document, setupDocument()) {
+		t.Error("Original document should not change. Got ", document)
+	}
+
+	if _, err = CloneAt(document, "one.missing"); err == nil {
+		t.Error("Should fail on missing property")
+	}
+}
+
+func TestRegisterCloneFunc(t *testing.T) {
+	type counter struct {
+		n int
+	}
+	calls := 0
+	RegisterCloneFunc(reflect.TypeOf(counter{}), func(v interface{}) interface{} {
+		calls++
+		return counter{n: v.(counter).n + 1}
+	})
+	defer RegisterCloneFunc(reflect.TypeOf(counter{}), nil)
+
+	copied := Clone(map[string]interface{}{
+		"c": counter{n: 1},
+	})
+	if calls != 1 || copied["c"].(counter).n != 2 {
+		t.Error("Registered clone function should be used. Got ", copied)
+	}
+}
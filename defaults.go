@@ -0,0 +1,93 @@
+package gjm
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ApplyDefaults creates properties of defaults missing from a document and
+// returns paths of the created properties. Existing properties, nil values
+// included, are never overwritten. Maps are merged recursively. An array of
+// defaults holding a single map is a template applied to every map element
+// of the document array: a missing array is created empty, the template is
+// never added as an element. Created values are deep copies.
+//
+//	filled, err := ApplyDefaults(config, map[string]interface{}{
+//		"server": map[string]interface{}{"port": 8080},
+//		"users":  []interface{}{map[string]interface{}{"role": "guest"}},
+//	})
+func ApplyDefaults(original_data map[string]interface{}, defaults map[string]interface{}) (filled []string, err error) {
+	filled = make([]string, 0)
+	err = applyDefaults(original_data, Path{}, reflect.ValueOf(defaults), &filled)
+	return
+}
+
+func applyDefaults(original_data map[string]interface{}, path Path, defaults reflect.Value, filled *[]string) error {
+	for defaults.IsValid() && defaults.Kind() == reflect.Interface && !defaults.IsNil() {
+		defaults = defaults.Elem()
+	}
+
+	var current interface{} = original_data
+	if len(path) > 0 {
+		var exists bool
+		if current, exists = getPath(original_data, path); !exists {
+			var value interface{}
+			if _, ok := arrayTemplate(defaults); ok {
+				value = make([]interface{}, 0)
+			} else if defaults.IsValid() {
+				value = cloneValue(defaults.Interface())
+			}
+			if err := setPath(original_data, path, value); err != nil {
+				return err
+			}
+			*filled = append(*filled, path.String())
+			return nil
+		}
+	}
+	if !defaults.IsValid() {
+		return nil
+	}
+	current_kind := reflect.ValueOf(current).Kind()
+
+	switch {
+	case defaults.Kind() == reflect.Map && isStringMap(defaults) && current_kind == reflect.Map:
+		keys := make([]string, 0, defaults.Len())
+		for _, key := range defaults.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := defaults.MapIndex(reflect.ValueOf(key).Convert(defaults.Type().Key()))
+			if err := applyDefaults(original_data, path.Child(key), value, filled); err != nil {
+				return err
+			}
+		}
+	case current_kind == reflect.Slice:
+		template, ok := arrayTemplate(defaults)
+		if !ok {
+			return nil
+		}
+		items := reflect.ValueOf(current)
+		for i := 0; i < items.Len(); i++ {
+			if reflect.ValueOf(items.Index(i).Interface()).Kind() != reflect.Map {
+				continue
+			}
+			if err := applyDefaults(original_data, path.Item(i), template, filled); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// arrayTemplate returns the map of an array of defaults holding a single map.
+func arrayTemplate(defaults reflect.Value) (reflect.Value, bool) {
+	if !defaults.IsValid() || (defaults.Kind() != reflect.Slice && defaults.Kind() != reflect.Array) || defaults.Len() != 1 {
+		return reflect.Value{}, false
+	}
+	template := defaults.Index(0)
+	for template.Kind() == reflect.Interface && !template.IsNil() {
+		template = template.Elem()
+	}
+	return template, template.Kind() == reflect.Map
+}
@@ -0,0 +1,152 @@
+package gjm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	document := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "example.com",
+			"tls":  nil,
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "john"},
+			map[string]interface{}{"name": "jane", "role": "admin"},
+			"not a map",
+		},
+		"tags": []interface{}{"a"},
+	}
+	defaults := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 8080,
+			"tls": map[string]interface{}{
+				"enabled": false,
+			},
+			"limits": map[string]interface{}{
+				"rps": 100,
+			},
+		},
+		"users": []interface{}{
+			map[string]interface{}{
+				"role":        "guest",
+				"preferences": map[string]interface{}{"theme": "dark"},
+			},
+		},
+		"tags":    []interface{}{"default"},
+		"logging": []interface{}{"stdout"},
+	}
+
+	filled, err := ApplyDefaults(document, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected_filled := []string{
+		"logging",
+		"server.limits",
+		"server.port",
+		"users[0].preferences",
+		"users[0].role",
+		"users[1].preferences",
+	}
+	if !reflect.DeepEqual(filled, expected_filled) {
+		t.Errorf("Filled paths should equal \n\t%v \n \n\t%v", filled, expected_filled)
+	}
+
+	expected := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host":   "example.com",
+			"port":   8080,
+			"tls":    nil,
+			"limits": map[string]interface{}{"rps": 100},
+		},
+		"users": []interface{}{
+			map[string]interface{}{
+				"name":        "john",
+				"role":        "guest",
+				"preferences": map[string]interface{}{"theme": "dark"},
+			},
+			map[string]interface{}{
+				"name":        "jane",
+				"role":        "admin",
+				"preferences": map[string]interface{}{"theme": "dark"},
+			},
+			"not a map",
+		},
+		"tags":    []interface{}{"a"},
+		"logging": []interface{}{"stdout"},
+	}
+	if !reflect.DeepEqual(document, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", document, expected)
+	}
+
+	UpdateProperty(document, "users[0].preferences.theme", "light")
+	if theme, _ := GetProperty(document, "users[1].preferences.theme"); theme != "dark" {
+		t.Error("Filled values should be copies")
+	}
+
+	filled, err = ApplyDefaults(document, defaults)
+	if err != nil || len(filled) != 0 {
+		t.Errorf("Applying defaults twice should fill nothing. Got %v, %v", filled, err)
+	}
+}
+
+func TestApplyDefaultsTypedArrays(t *testing.T) {
+	document := map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"id": 1},
+		},
+	}
+	filled, err := ApplyDefaults(document, map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"count": 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(filled, []string{"items[0].count"}) {
+		t.Error("Unexpected filled paths. Got ", filled)
+	}
+	if count, _ := GetProperty(document, "items[0].count"); count != 0 {
+		t.Error("Template should be applied to typed arrays. Got ", document)
+	}
+}
+
+func TestApplyDefaultsTemplateWithoutElements(t *testing.T) {
+	defaults := map[string]interface{}{
+		"users": []interface{}{map[string]interface{}{"role": "guest"}},
+	}
+	tests := []struct {
+		name            string
+		document        map[string]interface{}
+		expected_filled []string
+	}{
+		{
+			name:            "missing array",
+			document:        map[string]interface{}{},
+			expected_filled: []string{"users"},
+		},
+		{
+			name:            "empty array",
+			document:        map[string]interface{}{"users": []interface{}{}},
+			expected_filled: []string{},
+		},
+	}
+
+	expected := map[string]interface{}{"users": []interface{}{}}
+	for i, test := range tests {
+		filled, err := ApplyDefaults(test.document, defaults)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(filled, test.expected_filled) {
+			t.Errorf("\n[%d of %d: %s] Filled paths should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, filled, test.expected_filled)
+		}
+		if !reflect.DeepEqual(test.document, expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, test.document, expected)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package gjm
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Document wraps a document with a RWMutex making it safe for concurrent use.
+// Values are copied when they are stored and when they are returned,
+// so callers never share maps or slices with the document.
+//
+//	doc := NewDocument(config)
+//	err := doc.UpdateProperty("database.port", 5432)
+//	port, err := doc.GetProperty("database.port")
+type Document struct {
+	mu       sync.RWMutex
+	data     map[string]interface{}
+	watchers []*watcher
+	dropped  uint64
+	version  int
+	history  *history
+}
+
+// NewDocument creates a Document holding a deep copy of the passed document.
+func NewDocument(original_data map[string]interface{}, options ...DocumentOption) *Document {
+	data := Clone(original_data)
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	d := &Document{
+		data: data,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// GetProperty returns a deep copy of a property. See GetProperty.
+func (d *Document) GetProperty(path string, separator_arr ...string) (interface{}, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return CloneAt(d.data, path, separator_arr...)
+}
+
+// CreateProperty creates a property holding a deep copy of value. See CreateProperty.
+func (d *Document) CreateProperty(path string, value interface{}, separator_arr ...string) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.CreateProperty(path, value, separator_arr...)
+	})
+}
+
+// UpdateProperty creates or updates a property with a deep copy of value. See UpdateProperty.
+func (d *Document) UpdateProperty(path string, value interface{}, separator_arr ...string) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.UpdateProperty(path, value, separator_arr...)
+	})
+}
+
+// DeleteProperty removes a property. See DeleteProperty.
+func (d *Document) DeleteProperty(path string, separator_arr ...string) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.DeleteProperty(path, separator_arr...)
+	})
+}
+
+// Update runs fn holding the write lock. Changes made through tx are applied
+// atomically: if fn returns an error none of them are kept.
+//
+//	err := doc.Update(func(tx *Tx) error {
+//		if err := tx.UpdateProperty("a", 1); err != nil {
+//			return err
+//		}
+//		return tx.DeleteProperty("b")
+//	})
+func (d *Document) Update(fn func(tx *Tx) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx := &Tx{document: d, transaction: Begin(d.data)}
+	if err := fn(tx); err != nil {
+		tx.transaction.Rollback()
+		return err
+	}
+	before := tx.transaction.undo
+	if err := tx.transaction.Commit(); err != nil {
+		return err
+	}
+	if len(tx.regions) > 0 {
+		d.version++
+		if d.history != nil {
+			d.history.commit(before, tx.operations, tx.regions)
+		}
+	}
+	d.deliver(tx.events)
+	return nil
+}
+
+// Clone returns a deep copy of the whole document.
+func (d *Document) Clone() map[string]interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return Clone(d.data)
+}
+
+// MarshalJSON encodes the document.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return json.Marshal(d.data)
+}
+
+// Tx gives access to a Document inside Document.Update.
+// It must not be used after the function passed to Update returns.
+type Tx struct {
+	document    *Document
+	transaction *Transaction
+	events      []watchEvent
+	regions     []Path
+	operations  []PatchOperation
+}
+
+// GetProperty returns a property. See GetProperty.
+// The returned value must not be modified.
+func (tx *Tx) GetProperty(path string, separator_arr ...string) (interface{}, error) {
+	return tx.transaction.GetProperty(path, separator_arr...)
+}
+
+// CreateProperty creates a property holding a deep copy of value. See CreateProperty.
+func (tx *Tx) CreateProperty(path string, value interface{}, separator_arr ...string) error {
+	return tx.observe(path, separator_arr, func() error {
+		return tx.transaction.CreateProperty(path, cloneValue(value), separator_arr...)
+	})
+}
+
+// UpdateProperty creates or updates a property with a deep copy of value. See UpdateProperty.
+func (tx *Tx) UpdateProperty(path string, value interface{}, separator_arr ...string) error {
+	return tx.observe(path, separator_arr, func() error {
+		return tx.transaction.UpdateProperty(path, cloneValue(value), separator_arr...)
+	})
+}
+
+// DeleteProperty removes a property. See DeleteProperty.
+func (tx *Tx) DeleteProperty(path string, separator_arr ...string) error {
+	return tx.observe(path, separator_arr, func() error {
+		return tx.transaction.DeleteProperty(path, separator_arr...)
+	})
+}
+
+// observe runs a change, collecting events for watchers and the history of the document.
+func (tx *Tx) observe(path string, separator_arr []string, change func() error) error {
+	separator := getSeparator(separator_arr)
+	var recorder *patchRecorder
+	if tx.document.history != nil {
+		recorder = newPatchRecorder(tx.transaction.data, path, separator)
+	}
+
+	events, err := tx.document.observe(path, separator, change)
+	tx.events = append(tx.events, events...)
+	if err != nil {
+		return err
+	}
+
+	tx.regions = append(tx.regions, changedRegion(path, separator))
+	if recorder != nil {
+		tx.operations = append(tx.operations, recorder.operations(tx.transaction.data, separator)...)
+	}
+	return nil
+}
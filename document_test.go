@@ -0,0 +1,127 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	original := setupDocument()
+	doc := NewDocument(original)
+
+	if err := doc.UpdateProperty("one.two.three[0]", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.CreateProperty("one/five", "created", "/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.CreateProperty("one.five", "again"); err == nil {
+		t.Error("Creating an existing property should fail")
+	}
+	if err := doc.DeleteProperty("one.four"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(original, setupDocument()) {
+		t.Error("Passed document should not change")
+	}
+
+	expected := map[string]interface{}{
+		"one": map[string]interface{}{
+			"two": map[string]interface{}{
+				"three": []interface{}{100, 2, 3},
+			},
+			"five": "created",
+		},
+	}
+	if !reflect.DeepEqual(doc.Clone(), expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", doc.Clone(), expected)
+	}
+
+	two, _ := doc.GetProperty("one.two")
+	two.(map[string]interface{})["three"] = "changed"
+	value := map[string]interface{}{"a": 1}
+	doc.UpdateProperty("value", value)
+	value["a"] = 2
+	if !reflect.DeepEqual(doc.Clone()["one"], expected["one"]) {
+		t.Error("Returned values should be copies")
+	}
+	if a, _ := doc.GetProperty("value.a"); a != 1 {
+		t.Error("Stored values should be copies")
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil || string(encoded) != `{"one":{"five":"created","two":{"three":[100,2,3]}},"value":{"a":1}}` {
+		t.Errorf("Unexpected JSON %s, %v", encoded, err)
+	}
+}
+
+func TestDocumentUpdateIsAtomic(t *testing.T) {
+	doc := NewDocument(setupDocument())
+
+	err := doc.Update(func(tx *Tx) error {
+		if err := tx.UpdateProperty("one.two.three[0]", 100); err != nil {
+			return err
+		}
+		if err := tx.DeleteProperty("one.four"); err != nil {
+			return err
+		}
+		return tx.DeleteProperty("one.missing")
+	})
+	if !reflect.DeepEqual(err, fmt.Errorf("Property missing does not exist")) {
+		t.Error("Update should return the error. Got ", err)
+	}
+	if !reflect.DeepEqual(doc.Clone(), setupDocument()) {
+		t.Error("Failed update should not change the document. Got ", doc.Clone())
+	}
+
+	err = doc.Update(func(tx *Tx) error {
+		value, err := tx.GetProperty("one.four.five[1]")
+		if err != nil {
+			return err
+		}
+		return tx.UpdateProperty("one.sum", value.(int)+1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum, _ := doc.GetProperty("one.sum"); sum != 23 {
+		t.Error("Update should be applied. Got ", sum)
+	}
+}
+
+func TestDocumentConcurrency(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{
+		"counter": 0,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			doc.Update(func(tx *Tx) error {
+				counter, _ := tx.GetProperty("counter")
+				return tx.UpdateProperty("counter", counter.(int)+1)
+			})
+			doc.UpdateProperty(fmt.Sprintf("workers.w%d", i), i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			doc.GetProperty("counter")
+			doc.GetProperty("workers")
+			json.Marshal(doc)
+		}()
+	}
+	wg.Wait()
+
+	if counter, _ := doc.GetProperty("counter"); counter != 20 {
+		t.Error("Every update should be applied. Got ", counter)
+	}
+	if workers, _ := doc.GetProperty("workers"); len(workers.(map[string]interface{})) != 20 {
+		t.Error("Every worker should be stored. Got ", workers)
+	}
+}
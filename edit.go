@@ -0,0 +1,408 @@
+package gjm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// SetBytes sets a property of a JSON document without decoding it.
+// Value is encoded with json.Marshal, without escaping HTML, and spliced
+// into a copy of data: formatting, key order and every other byte are kept.
+// Missing objects are created and arrays are padded with null the same way
+// CreateProperty does. Inserted members are separated like their siblings.
+// `[+]` and `[-]` in place of an index append to the array.
+//
+//	body, err := SetBytes(body, "meta.request_id", id)
+//	body, err := SetBytes(body, "meta.tags[+]", "proxied")
+func SetBytes(data []byte, path string, value interface{}, separator_arr ...string) ([]byte, error) {
+	separator := getSeparator(separator_arr)
+	levels := make([]string, 0)
+	for level, next := nextLevel(path, separator, 0); len(level) > 0; level, next = nextLevel(path, separator, next) {
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("Property %s does not exist", path)
+	}
+
+	s := &scanner{data: data}
+	if s.peek() != '{' {
+		return nil, fmt.Errorf("JSON document is not an object")
+	}
+
+	for i, level := range levels {
+		last := i == len(levels)-1
+		property, index, indexed, err := parseBytesLevel(level)
+		if err != nil {
+			return nil, err
+		}
+
+		object, err := scanMembers(s)
+		if err != nil {
+			return nil, err
+		}
+		m := object.find(property)
+		if m == nil {
+			member, err := buildMember(levels[i:], value)
+			if err != nil {
+				return nil, err
+			}
+			return object.insert(data, property, member)
+		}
+
+		if !indexed {
+			if last {
+				return spliceValue(data, m.value, value)
+			}
+			switch data[m.value[0]] {
+			case '{':
+				s.pos = m.value[0]
+				continue
+			case 'n':
+				nested, err := buildValue(levels[i+1:], value)
+				if err != nil {
+					return nil, err
+				}
+				return spliceValue(data, m.value, nested)
+			}
+			return nil, fmt.Errorf("%s: is not an object", property)
+		}
+
+		switch data[m.value[0]] {
+		case 'n':
+			member, err := buildMember(levels[i:], value)
+			if err != nil {
+				return nil, err
+			}
+			return spliceValue(data, m.value, member)
+		case '[':
+		default:
+			return nil, fmt.Errorf("%s: is not an array", property)
+		}
+
+		array, err := scanElements(&scanner{data: data, pos: m.value[0]})
+		if err != nil {
+			return nil, err
+		}
+		if index == appendIndex {
+			index = len(array.items)
+		}
+		if index >= len(array.items) {
+			element, err := buildValue(levels[i+1:], value)
+			if err != nil {
+				return nil, err
+			}
+			padding := make([]interface{}, index-len(array.items)+1)
+			padding[len(padding)-1] = element
+			return array.append(data, padding)
+		}
+
+		item := array.items[index]
+		if last {
+			return spliceValue(data, item.value, value)
+		}
+		switch data[item.value[0]] {
+		case '{':
+			s.pos = item.value[0]
+			continue
+		case 'n':
+			nested, err := buildValue(levels[i+1:], value)
+			if err != nil {
+				return nil, err
+			}
+			return spliceValue(data, item.value, nested)
+		}
+		return nil, fmt.Errorf("%s: is not an object", level)
+	}
+	return nil, fmt.Errorf("Property %s does not exist", path)
+}
+
+// DeleteBytes removes a property of a JSON document without decoding it.
+// Like DeleteProperty an array element is removed shifting the following ones.
+// Every other byte of data is kept. Errors are the ones of GetBytes. Paths can
+// not use the `[+]` and `[-]` append tokens: there is no element to remove.
+//
+//	body, err := DeleteBytes(body, "meta.debug")
+func DeleteBytes(data []byte, path string, separator_arr ...string) ([]byte, error) {
+	separator := getSeparator(separator_arr)
+	levels := make([]string, 0)
+	for level, next := nextLevel(path, separator, 0); len(level) > 0; level, next = nextLevel(path, separator, next) {
+		if appendTokenRe.MatchString(level) {
+			return nil, fmt.Errorf("%s: [+] and [-] append to an array and can not be removed", path)
+		}
+		levels = append(levels, level)
+	}
+	if _, _, err := findBytes(data, path, separator); err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("Property %s does not exist", path)
+	}
+
+	// The path exists, move to the object holding its last level
+	s := &scanner{data: data}
+	for _, level := range levels[:len(levels)-1] {
+		property, index, indexed, _ := parseBytesLevel(level)
+		s.findKey(property)
+		if indexed {
+			s.findIndex(index)
+		}
+	}
+
+	property, index, indexed, _ := parseBytesLevel(levels[len(levels)-1])
+	object, err := scanMembers(s)
+	if err != nil {
+		return nil, err
+	}
+	m := object.find(property)
+	if !indexed {
+		return object.remove(data, m), nil
+	}
+	array, err := scanElements(&scanner{data: data, pos: m.value[0]})
+	if err != nil {
+		return nil, err
+	}
+	return array.remove(data, &array.items[index]), nil
+}
+
+// parseBytesLevel splits a level the way GetProperty does.
+// `property[+]` and `property[-]` levels have the index appendIndex.
+func parseBytesLevel(level string) (string, int, bool, error) {
+	if matched := appendTokenRe.FindStringSubmatch(level); matched != nil {
+		return matched[1], appendIndex, true, nil
+	}
+	property, index_found, indexed := parseIndexLevel(level)
+	if !indexed {
+		return level, 0, false, nil
+	}
+	index, err := strconv.Atoi(index_found)
+	if err != nil {
+		return "", 0, false, fmt.Errorf(
+			"%s must be of type %s",
+			fmt.Sprintf("%s[%s]", property, index_found),
+			"number",
+		)
+	}
+	return property, index, true, nil
+}
+
+// buildValue builds the value created for levels holding value.
+func buildValue(levels []string, value interface{}) (interface{}, error) {
+	if len(levels) == 0 {
+		return value, nil
+	}
+	member, err := buildMember(levels, value)
+	if err != nil {
+		return nil, err
+	}
+	property, _, _, _ := parseBytesLevel(levels[0])
+	return map[string]interface{}{property: member}, nil
+}
+
+// buildMember builds the value of the property of the first level of levels.
+func buildMember(levels []string, value interface{}) (interface{}, error) {
+	_, index, indexed, err := parseBytesLevel(levels[0])
+	if err != nil {
+		return nil, err
+	}
+	child, err := buildValue(levels[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	if !indexed {
+		return child, nil
+	}
+	if index == appendIndex {
+		index = 0
+	}
+	items := make([]interface{}, index+1)
+	items[index] = child
+	return items, nil
+}
+
+// jsonMember is an object member or an array element of a JSON document.
+type jsonMember struct {
+	key   string
+	start int
+	colon [2]int
+	value [2]int
+}
+
+// jsonContainer is an object or an array of a JSON document.
+type jsonContainer struct {
+	open  int
+	close int
+	items []jsonMember
+}
+
+// scanMembers reads the members of the object starting at the position of the scanner.
+func scanMembers(s *scanner) (*jsonContainer, error) {
+	if s.peek() != '{' {
+		return nil, s.syntaxError()
+	}
+	object := &jsonContainer{open: s.pos}
+	s.pos++
+	if s.peek() == '}' {
+		object.close = s.pos
+		s.pos++
+		return object, nil
+	}
+	for {
+		s.skipSpace()
+		m := jsonMember{start: s.pos}
+		key, err := s.readString()
+		if err != nil {
+			return nil, err
+		}
+		m.key = key
+		m.colon[0] = s.pos
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+		s.skipSpace()
+		m.colon[1] = s.pos
+		m.value[0] = s.pos
+		if err := s.skipValue(); err != nil {
+			return nil, err
+		}
+		m.value[1] = s.pos
+		object.items = append(object.items, m)
+
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case '}':
+			object.close = s.pos
+			s.pos++
+			return object, nil
+		default:
+			return nil, s.syntaxError()
+		}
+	}
+}
+
+// scanElements reads the elements of the array starting at the position of the scanner.
+func scanElements(s *scanner) (*jsonContainer, error) {
+	array := &jsonContainer{open: s.pos}
+	spans, err := scanArray(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, span := range spans {
+		array.items = append(array.items, jsonMember{start: span[0], value: span})
+	}
+	array.close = s.pos - 1
+	return array, nil
+}
+
+func (c *jsonContainer) find(key string) *jsonMember {
+	for i := len(c.items) - 1; i >= 0; i-- {
+		if c.items[i].key == key {
+			return &c.items[i]
+		}
+	}
+	return nil
+}
+
+// insert adds a member at the end of an object.
+func (c *jsonContainer) insert(data []byte, key string, value interface{}) ([]byte, error) {
+	encoded_key, err := encodeValue(key)
+	if err != nil {
+		return nil, err
+	}
+	encoded_value, err := encodeValue(value)
+	if err != nil {
+		return nil, err
+	}
+	colon := []byte(":")
+	if len(c.items) > 0 {
+		first := c.items[0]
+		colon = data[first.colon[0]:first.colon[1]]
+	}
+	member := append(append(encoded_key, colon...), encoded_value...)
+	return c.add(data, [][]byte{member}), nil
+}
+
+// append adds elements at the end of an array.
+func (c *jsonContainer) append(data []byte, values []interface{}) ([]byte, error) {
+	elements := make([][]byte, len(values))
+	for i, value := range values {
+		encoded, err := encodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = encoded
+	}
+	return c.add(data, elements), nil
+}
+
+// add inserts encoded items after the last item, separated like the existing ones.
+func (c *jsonContainer) add(data []byte, items [][]byte) []byte {
+	position := c.open + 1
+	var indent []byte
+	switch {
+	case len(c.items) > 1:
+		position = c.items[len(c.items)-1].value[1]
+		indent = data[c.items[0].value[1]:c.items[1].start]
+		indent = indent[bytes.IndexByte(indent, ',')+1:]
+	case len(c.items) == 1:
+		position = c.items[0].value[1]
+		indent = data[c.open+1 : c.items[0].start]
+	}
+
+	inserted := make([]byte, 0)
+	for i, item := range items {
+		if i > 0 || len(c.items) > 0 {
+			inserted = append(inserted, ',')
+			inserted = append(inserted, indent...)
+		}
+		inserted = append(inserted, item...)
+	}
+	return splice(data, position, position, inserted)
+}
+
+// remove removes an item with the comma and indentation separating it from its siblings.
+func (c *jsonContainer) remove(data []byte, item *jsonMember) []byte {
+	if len(c.items) == 1 {
+		return splice(data, c.open+1, c.close, nil)
+	}
+	for i := range c.items {
+		if &c.items[i] != item {
+			continue
+		}
+		if i < len(c.items)-1 {
+			return splice(data, item.start, c.items[i+1].start, nil)
+		}
+		return splice(data, c.items[i-1].value[1], item.value[1], nil)
+	}
+	return data
+}
+
+// spliceValue replaces a value with an encoded one.
+func spliceValue(data []byte, span [2]int, value interface{}) ([]byte, error) {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return splice(data, span[0], span[1], encoded), nil
+}
+
+// encodeValue encodes a value like json.Marshal without escaping HTML characters.
+func encodeValue(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// splice returns a copy of data with data[start:end] replaced.
+func splice(data []byte, start int, end int, replacement []byte) []byte {
+	result := make([]byte, 0, len(data)-(end-start)+len(replacement))
+	result = append(result, data[:start]...)
+	result = append(result, replacement...)
+	return append(result, data[end:]...)
+}
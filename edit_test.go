@@ -0,0 +1,153 @@
+package gjm
+
+import (
+	"fmt"
+	"testing"
+)
+
+var testEditPayload = []byte("{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null\n}")
+
+func TestSetBytes(t *testing.T) {
+	tests := []MapTest{
+		{
+			path:  "meta.request_id",
+			value: "abc",
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1,\n\t\t\"request_id\": \"abc\"\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:  "meta.id",
+			value: "<b>",
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": \"<b>\"\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:  "new.deep[1].x",
+			value: true,
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null,\n\t\"new\": {\"deep\":[null,{\"x\":true}]}\n}",
+		},
+		{
+			path:  "items[3]",
+			value: 4,
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2, null, 4],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:      "empty/a",
+			separator: "/",
+			value:     1,
+			out:       "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {\"a\":1},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:  "nothing.a[1]",
+			value: 1,
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": {\"a\":[null,1]}\n}",
+		},
+		{
+			path:  "items[+]",
+			value: 3,
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2, 3],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:  "meta.tags[-].name",
+			value: "a",
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1,\n\t\t\"tags\": [{\"name\":\"a\"}]\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path:  "nothing[+]",
+			value: 1,
+			out:   "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": [1]\n}",
+		},
+		{
+			path: "items.a",
+			err:  fmt.Errorf("items: is not an object"),
+		},
+		{
+			path: "meta[+]",
+			err:  fmt.Errorf("meta: is not an array"),
+		},
+		{
+			path: "meta[0]",
+			err:  fmt.Errorf("meta: is not an array"),
+		},
+		{
+			path: "meta.id.x",
+			err:  fmt.Errorf("id: is not an object"),
+		},
+	}
+
+	for i, test := range tests {
+		out, err := SetBytes(testEditPayload, test.path, test.value, test.separator)
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if string(out) != test.out {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%s \n \n\t%s", i+1, len(tests), test.path, out, test.out)
+		}
+	}
+}
+
+func TestDeleteBytes(t *testing.T) {
+	tests := []MapTest{
+		{
+			path: "meta.id",
+			out:  "{\n\t\"meta\": {},\n\t\"items\": [1, 2],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path: "items[0]",
+			out:  "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [2],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path: "items[1]",
+			out:  "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1],\n\t\"empty\": {},\n\t\"nothing\": null\n}",
+		},
+		{
+			path: "empty",
+			out:  "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"nothing\": null\n}",
+		},
+		{
+			path: "nothing",
+			out:  "{\n\t\"meta\": {\n\t\t\"id\": 1\n\t},\n\t\"items\": [1, 2],\n\t\"empty\": {}\n}",
+		},
+		{
+			path: "missing",
+			err:  fmt.Errorf("Property missing does not exist"),
+		},
+		{
+			path: "items[2]",
+			err:  fmt.Errorf("items: Min index is 0, Max index is 2. You passed index 2"),
+		},
+		{
+			path: "items[+]",
+			err:  fmt.Errorf("items[+]: [+] and [-] append to an array and can not be removed"),
+		},
+	}
+
+	for i, test := range tests {
+		out, err := DeleteBytes(testEditPayload, test.path, test.separator)
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if string(out) != test.out {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%s \n \n\t%s", i+1, len(tests), test.path, out, test.out)
+		}
+	}
+
+	original := string(testEditPayload)
+	DeleteBytes(testEditPayload, "meta")
+	SetBytes(testEditPayload, "meta.id", 2)
+	if string(testEditPayload) != original {
+		t.Error("Passed data should not change")
+	}
+}
@@ -0,0 +1,357 @@
+package gjm
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EqualOption changes how Equal compares documents.
+type EqualOption func(*equalOptions)
+
+type equalOptions struct {
+	nil_equals_missing bool
+	nil_equals_empty   bool
+	ignore_paths       []*Pattern
+}
+
+// NilEqualsMissing treats a property set to nil as equal to a missing property.
+func NilEqualsMissing() EqualOption {
+	return func(o *equalOptions) {
+		o.nil_equals_missing = true
+	}
+}
+
+// NilEqualsEmpty treats nil as equal to an empty array or an empty map.
+func NilEqualsEmpty() EqualOption {
+	return func(o *equalOptions) {
+		o.nil_equals_empty = true
+	}
+}
+
+// IgnorePaths skips properties matching the given patterns and everything below them.
+// Patterns use the default separator, invalid patterns are ignored.
+//
+//	IgnorePaths("meta.timestamp", "users[*].last_login", "**.etag")
+func IgnorePaths(patterns ...string) EqualOption {
+	return func(o *equalOptions) {
+		for _, pattern := range patterns {
+			if compiled, err := CompilePattern(pattern); err == nil {
+				o.ignore_paths = append(o.ignore_paths, compiled.withDescendants())
+			}
+		}
+	}
+}
+
+// Equal reports whether two documents or values are deeply equal.
+// Unlike reflect.DeepEqual numbers are compared by value, so `int(1)`, `float64(1)`
+// and `json.Number("1")` are equal, and typed maps and slices are equal
+// to `map[string]interface{}` and `[]interface{}` with the same content.
+// A json.Number with an exponent beyond 1000, like `1e1000000`, is compared
+// as a string: converting it exactly would take a million digits.
+//
+//	Equal(built, decoded)
+//	Equal(built, decoded, NilEqualsMissing(), IgnorePaths("meta.timestamp"))
+func Equal(a, b interface{}, opts ...EqualOption) bool {
+	options := &equalOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options.equal(Path{}, reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func (o *equalOptions) ignored(path Path) bool {
+	for _, ignore_path := range o.ignore_paths {
+		if ignore_path.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *equalOptions) equal(path Path, a, b reflect.Value) bool {
+	if len(path) > 0 && o.ignored(path) {
+		return true
+	}
+
+	a, b = indirect(a), indirect(b)
+
+	a_nil, b_nil := isNilValue(a), isNilValue(b)
+	if a_nil || b_nil {
+		if a_nil && b_nil {
+			return true
+		}
+		if o.nil_equals_empty {
+			// nil is empty, so both sides must be
+			return isEmptyContainer(a) && isEmptyContainer(b)
+		}
+		return false
+	}
+
+	if a_number, ok := toRat(a); ok {
+		b_number, ok := toRat(b)
+		return ok && equalRat(a_number, b_number)
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return b.Kind() == reflect.String && a.String() == b.String()
+	case reflect.Bool:
+		return b.Kind() == reflect.Bool && a.Bool() == b.Bool()
+	case reflect.Map:
+		if b.Kind() != reflect.Map || !isStringMap(a) || !isStringMap(b) {
+			break
+		}
+		a_map, b_map := stringMap(a), stringMap(b)
+		for key, a_value := range a_map {
+			b_value, ok := b_map[key]
+			if !ok {
+				if !(o.nil_equals_missing && isNilValue(indirect(a_value))) && !o.ignored(path.Child(key)) {
+					return false
+				}
+				continue
+			}
+			if !o.equal(path.Child(key), a_value, b_value) {
+				return false
+			}
+		}
+		for key, b_value := range b_map {
+			if _, ok := a_map[key]; !ok {
+				if !(o.nil_equals_missing && isNilValue(indirect(b_value))) && !o.ignored(path.Child(key)) {
+					return false
+				}
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if b.Kind() != reflect.Slice && b.Kind() != reflect.Array {
+			return false
+		}
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !o.equal(path.Item(i), a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return false
+}
+
+// Hash returns a stable hash of a document or a value.
+// Values which are Equal without options have the same hash.
+// Cyclic documents are not supported.
+//
+//	key := Hash(document)
+func Hash(value interface{}) uint64 {
+	h := fnv.New64a()
+	writeHash(h, reflect.ValueOf(value))
+	return h.Sum64()
+}
+
+func writeHash(w io.Writer, v reflect.Value) {
+	v = indirect(v)
+
+	if isNilValue(v) {
+		io.WriteString(w, "n")
+		return
+	}
+
+	if number, ok := toRat(v); ok {
+		if number == nil {
+			// NaN
+			io.WriteString(w, "N")
+			return
+		}
+		writeHashString(w, "i", number.RatString())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		writeHashString(w, "s", v.String())
+		return
+	case reflect.Bool:
+		if v.Bool() {
+			io.WriteString(w, "t")
+		} else {
+			io.WriteString(w, "f")
+		}
+		return
+	case reflect.Map:
+		if !isStringMap(v) {
+			break
+		}
+		m := stringMap(v)
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeHashLength(w, "m", len(keys))
+		for _, key := range keys {
+			writeHashString(w, "s", key)
+			writeHash(w, m[key])
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		writeHashLength(w, "a", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			writeHash(w, v.Index(i))
+		}
+		return
+	}
+
+	if v.CanInterface() {
+		if encoded, err := json.Marshal(v.Interface()); err == nil {
+			writeHashString(w, "j", string(encoded))
+			return
+		}
+	}
+	writeHashString(w, "v", fmt.Sprintf("%#v", v))
+}
+
+func writeHashString(w io.Writer, kind string, s string) {
+	writeHashLength(w, kind, len(s))
+	io.WriteString(w, s)
+}
+
+func writeHashLength(w io.Writer, kind string, length int) {
+	io.WriteString(w, kind)
+	binary.Write(w, binary.LittleEndian, uint64(length))
+}
+
+// indirect unwraps interfaces and pointers to non-container values.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && !v.IsNil() {
+		if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+			if _, ok := toRat(v); ok {
+				return v
+			}
+			break
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isEmptyContainer(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	}
+	return isNilValue(v)
+}
+
+func isStringMap(v reflect.Value) bool {
+	return v.Type().Key().Kind() == reflect.String
+}
+
+func stringMap(v reflect.Value) map[string]reflect.Value {
+	m := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value()
+	}
+	return m
+}
+
+var (
+	json_number_type = reflect.TypeOf(json.Number(""))
+	big_int_type     = reflect.TypeOf(&big.Int{})
+	big_float_type   = reflect.TypeOf(&big.Float{})
+)
+
+// toRat converts a numeric value to a rational number.
+// NaN converts to nil, infinities are not numbers.
+func toRat(v reflect.Value) (*big.Rat, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	switch v.Type() {
+	case json_number_type:
+		return parseNumber(v.String())
+	case big_int_type:
+		return new(big.Rat).SetInt(v.Interface().(*big.Int)), true
+	case big_float_type:
+		f := v.Interface().(*big.Float)
+		if f.IsInf() {
+			return nil, false
+		}
+		number, _ := f.Rat(nil)
+		return number, true
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) {
+			return nil, true
+		}
+		if math.IsInf(f, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(f), true
+	}
+	return nil, false
+}
+
+// maxNumberExponent bounds the exponent of the numbers parseNumber converts.
+const maxNumberExponent = 1000
+
+// parseNumber converts the text of a json.Number exactly. Integers fitting
+// in an int64 or a uint64 are parsed without big.Rat, numbers with an
+// exponent beyond maxNumberExponent are rejected before big.Rat expands them.
+func parseNumber(s string) (*big.Rat, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return new(big.Rat).SetInt64(i), true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return new(big.Rat).SetUint64(u), true
+	}
+	if e := strings.IndexAny(s, "eE"); e >= 0 {
+		exponent, err := strconv.Atoi(s[e+1:])
+		if err != nil || exponent > maxNumberExponent || exponent < -maxNumberExponent {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(s)
+}
+
+func equalRat(a, b *big.Rat) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(b) == 0
+}
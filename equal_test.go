@@ -0,0 +1,160 @@
+package gjm
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a    interface{}
+		b    interface{}
+		opts []EqualOption
+		out  bool
+	}{
+		{a: 1, b: float64(1), out: true},
+		{a: uint8(1), b: json.Number("1.0"), out: true},
+		{a: json.Number("18446744073709551615"), b: uint64(18446744073709551615), out: true},
+		{a: json.Number("1e1000"), b: json.Number("10e999"), out: true},
+		{a: json.Number("1e1000000"), b: json.Number("1e1000000"), out: true},
+		{a: json.Number("1e1000000"), b: json.Number("10e999999"), out: false},
+		{a: 1, b: 1.5, out: false},
+		{a: 1, b: "1", out: false},
+		{a: math.NaN(), b: math.NaN(), out: true},
+		{a: math.Inf(1), b: math.Inf(1), out: true},
+		{a: []int{1, 2}, b: []interface{}{1.0, 2.0}, out: true},
+		{a: []int{1, 2}, b: []interface{}{1.0}, out: false},
+		{a: [2]int{1, 2}, b: []interface{}{1.0, 2.0}, out: true},
+		{
+			a:   map[string][]string{"a": {"b"}},
+			b:   map[string]interface{}{"a": []interface{}{"b"}},
+			out: true,
+		},
+		{a: setupDocument(), b: setupDocument(), out: true},
+		{a: setupDocument(), b: setupDocument_I(), out: false},
+		{
+			a:   map[string]interface{}{"a": nil},
+			b:   map[string]interface{}{},
+			out: false,
+		},
+		{
+			a:    map[string]interface{}{"a": nil},
+			b:    map[string]interface{}{},
+			opts: []EqualOption{NilEqualsMissing()},
+			out:  true,
+		},
+		{
+			a:   map[string]interface{}{"a": nil},
+			b:   map[string]interface{}{"a": []interface{}{}},
+			out: false,
+		},
+		{
+			a:    map[string]interface{}{"a": nil},
+			b:    map[string]interface{}{"a": []interface{}{}},
+			opts: []EqualOption{NilEqualsEmpty()},
+			out:  true,
+		},
+		{a: []int(nil), b: nil, out: true},
+		{
+			a:    []interface{}(nil),
+			b:    []interface{}{1},
+			opts: []EqualOption{NilEqualsEmpty()},
+			out:  false,
+		},
+		{
+			a:    map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+			b:    map[string]interface{}{"a": map[string]interface{}(nil)},
+			opts: []EqualOption{NilEqualsEmpty()},
+			out:  false,
+		},
+		{
+			a:    map[string]interface{}{"a": nil},
+			b:    map[string]interface{}{"a": "text"},
+			opts: []EqualOption{NilEqualsEmpty()},
+			out:  false,
+		},
+		{
+			a:    map[string]interface{}{"a": map[string]interface{}{"t": 1, "x": 1}, "b": 2},
+			b:    map[string]interface{}{"a": map[string]interface{}{"t": 2, "x": 1}},
+			opts: []EqualOption{IgnorePaths("a.t", "b")},
+			out:  true,
+		},
+		{
+			a:    map[string]interface{}{"a": []interface{}{1, 2}},
+			b:    map[string]interface{}{"a": []interface{}{1, 3}},
+			opts: []EqualOption{IgnorePaths("a[1]")},
+			out:  true,
+		},
+		{
+			a:    map[string]interface{}{"a": []interface{}{map[string]interface{}{"etag": 1, "v": 1}}},
+			b:    map[string]interface{}{"a": []interface{}{map[string]interface{}{"etag": 2, "v": 1}}},
+			opts: []EqualOption{IgnorePaths("**.etag")},
+			out:  true,
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		if out := Equal(c.a, c.b, c.opts...); out != c.out {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+		if out := Equal(c.b, c.a, c.opts...); out != c.out {
+			t.Errorf("\n[%d of %d: Equal should be symmetric] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+}
+
+func TestEqualDecodedDocument(t *testing.T) {
+	built := map[string]interface{}{}
+	UpdateProperty(built, "user.age", 42)
+	UpdateProperty(built, "user.scores", []int{1, 2})
+
+	decoded := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"user":{"age":42,"scores":[1,2]}}`), &decoded)
+
+	if !Equal(built, decoded) {
+		t.Errorf("Built and decoded documents should be equal \n\t%v \n \n\t%v", built, decoded)
+	}
+	if Hash(built) != Hash(decoded) {
+		t.Error("Built and decoded documents should have the same hash")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash(setupDocument()) != Hash(setupDocument()) {
+		t.Error("Hash should be stable")
+	}
+	if Hash(setupDocument()) == Hash(setupDocument_I()) {
+		t.Error("Different documents should have different hashes")
+	}
+	if Hash(map[string]interface{}{"a": "b"}) == Hash(map[string]interface{}{"ab": ""}) {
+		t.Error("Hash should not depend on concatenation")
+	}
+	if Hash([]interface{}{"1"}) == Hash([]interface{}{1}) {
+		t.Error("Strings and numbers should have different hashes")
+	}
+	if Hash(1.5) != Hash(json.Number("1.50")) {
+		t.Error("Equal numbers should have the same hash")
+	}
+}
+
+func TestEqualLargeExponents(t *testing.T) {
+	numbers := []interface{}{
+		json.Number("1e1000000"), json.Number("-1e-1000000"), json.Number("1.5E+999999999"),
+		json.Number("1e99999999999999999999"), json.Number("2e1000001"),
+	}
+
+	start := time.Now()
+	for _, number := range numbers {
+		Equal(number, 1)
+		Equal(number, number)
+		Hash(number)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Numbers with large exponents should not be expanded, took %v", elapsed)
+	}
+}
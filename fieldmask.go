@@ -0,0 +1,221 @@
+package gjm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldMask is a set of paths selecting parts of a document,
+// modelled after google.protobuf.FieldMask.
+// Paths are normalized: sorted, without duplicates and without
+// paths covered by another path of the mask.
+//
+//	mask, err := ParseFieldMask("user.profile.name,user.settings")
+type FieldMask struct {
+	paths []Path
+}
+
+// ParseFieldMask parses comma separated paths. Commas inside keys are escaped with `\`.
+//
+//	mask, err := ParseFieldMask("user.profile.name,user.settings")
+//	mask, err := ParseFieldMask("user/profile/name,user/settings", "/")
+func ParseFieldMask(mask string, separator_arr ...string) (FieldMask, error) {
+	paths := make([]string, 0)
+	var b strings.Builder
+	for i := 0; i < len(mask); i++ {
+		switch mask[i] {
+		case '\\':
+			b.WriteByte(mask[i])
+			if i+1 < len(mask) {
+				i++
+				b.WriteByte(mask[i])
+			}
+		case ',':
+			paths = append(paths, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(mask[i])
+		}
+	}
+	paths = append(paths, b.String())
+
+	return NewFieldMask(paths, separator_arr...)
+}
+
+// NewFieldMask creates a mask from paths. Empty paths are skipped.
+//
+//	mask, err := NewFieldMask([]string{"user.profile.name", "user.settings"})
+func NewFieldMask(paths []string, separator_arr ...string) (FieldMask, error) {
+	parsed := make([]Path, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if len(path) == 0 {
+			continue
+		}
+		p, err := ParsePath(path, separator_arr...)
+		if err != nil {
+			return FieldMask{}, err
+		}
+		if len(p) == 0 {
+			continue
+		}
+		if p[0].IsIndex {
+			return FieldMask{}, fmt.Errorf(
+				"Property %s must start with a key", path,
+			)
+		}
+		parsed = append(parsed, p)
+	}
+	return FieldMask{paths: normalizePaths(parsed)}, nil
+}
+
+// Paths returns the paths of the mask formatted with the default separator.
+func (m FieldMask) Paths() []string {
+	paths := make([]string, len(m.paths))
+	for i, path := range m.paths {
+		paths[i] = path.String()
+	}
+	return paths
+}
+
+// String returns comma separated paths of the mask.
+func (m FieldMask) String() string {
+	paths := m.Paths()
+	for i, path := range paths {
+		paths[i] = strings.Replace(path, ",", `\,`, -1)
+	}
+	return strings.Join(paths, ",")
+}
+
+// IsEmpty reports whether the mask has no paths.
+func (m FieldMask) IsEmpty() bool {
+	return len(m.paths) == 0
+}
+
+// Contains reports whether a path is selected by the mask.
+func (m FieldMask) Contains(path string, separator_arr ...string) bool {
+	parsed, err := ParsePath(path, separator_arr...)
+	if err != nil {
+		return false
+	}
+	for _, mask_path := range m.paths {
+		if parsed.HasPrefix(mask_path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns a new document with copies of the masked properties only.
+// Masked properties missing from the document are skipped.
+//
+//	projected := mask.Apply(document)
+func (m FieldMask) Apply(original_data map[string]interface{}) map[string]interface{} {
+	projected := make(map[string]interface{})
+	for _, path := range m.paths {
+		if value, ok := getPath(original_data, path); ok {
+			setPath(projected, path, cloneValue(value))
+		}
+	}
+	return projected
+}
+
+// Merge copies masked properties from src into dst. A masked property
+// replaces the whole subtree in dst. Masked properties missing from src
+// are removed from dst.
+//
+//	err := mask.Merge(stored, request)
+func (m FieldMask) Merge(dst, src map[string]interface{}) error {
+	for _, path := range m.paths {
+		value, ok := getPath(src, path)
+		if !ok {
+			deletePath(dst, path)
+			continue
+		}
+		if err := setPath(dst, path, cloneValue(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Union returns a mask selecting properties selected by any of the masks.
+func (m FieldMask) Union(other FieldMask) FieldMask {
+	paths := make([]Path, 0, len(m.paths)+len(other.paths))
+	paths = append(paths, m.paths...)
+	paths = append(paths, other.paths...)
+	return FieldMask{paths: normalizePaths(paths)}
+}
+
+// Intersect returns a mask selecting properties selected by both masks.
+func (m FieldMask) Intersect(other FieldMask) FieldMask {
+	paths := make([]Path, 0)
+	for _, a := range m.paths {
+		for _, b := range other.paths {
+			if a.HasPrefix(b) {
+				paths = append(paths, a)
+			} else if b.HasPrefix(a) {
+				paths = append(paths, b)
+			}
+		}
+	}
+	return FieldMask{paths: normalizePaths(paths)}
+}
+
+// Validate checks that every masked property exists in a document.
+//
+//	if err := mask.Validate(schema); err != nil {
+//		// Property user.unknown does not exist
+//	}
+func (m FieldMask) Validate(original_data map[string]interface{}) error {
+	for _, path := range m.paths {
+		if _, ok := getPath(original_data, path); !ok {
+			return fmt.Errorf(
+				"Property %s does not exist", path,
+			)
+		}
+	}
+	return nil
+}
+
+// normalizePaths sorts paths and removes duplicates and paths covered by other paths.
+func normalizePaths(paths []Path) []Path {
+	sort.Slice(paths, func(i, j int) bool {
+		return comparePaths(paths[i], paths[j]) < 0
+	})
+
+	normalized := make([]Path, 0, len(paths))
+	for _, path := range paths {
+		if len(normalized) > 0 && path.HasPrefix(normalized[len(normalized)-1]) {
+			continue
+		}
+		normalized = append(normalized, path)
+	}
+	return normalized
+}
+
+// comparePaths orders paths level by level, keys before indexes.
+func comparePaths(a, b Path) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		switch {
+		case x.IsIndex != y.IsIndex:
+			if y.IsIndex {
+				return -1
+			}
+			return 1
+		case x.IsIndex && x.Index != y.Index:
+			if x.Index < y.Index {
+				return -1
+			}
+			return 1
+		case !x.IsIndex && x.Key != y.Key:
+			if x.Key < y.Key {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,184 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldMask(t *testing.T) {
+	cases := []struct {
+		mask      string
+		separator string
+		out       []string
+		err       error
+	}{
+		{
+			mask: "user.profile.name,user.settings",
+			out:  []string{"user.profile.name", "user.settings"},
+		},
+		{
+			mask: " user.settings.theme , user.settings,,user.settings ",
+			out:  []string{"user.settings"},
+		},
+		{
+			mask:      "user/profile/first.name,a\\,b",
+			separator: "/",
+			out:       []string{"a,b", `user.profile.first\.name`},
+		},
+		{
+			mask: "items[1].name,items[0]",
+			out:  []string{"items[0]", "items[1].name"},
+		},
+		{
+			mask: "[0].name",
+			err:  fmt.Errorf("Property [0].name must start with a key"),
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		mask, err := ParseFieldMask(c.mask, c.separator)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err, c.err)
+		}
+		if err == nil && !reflect.DeepEqual(mask.Paths(), c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, mask.Paths(), c.out)
+		}
+	}
+
+	mask, _ := ParseFieldMask("a\\,b,c")
+	if mask.String() != "a\\,b,c" {
+		t.Error("String should escape commas. Got ", mask.String())
+	}
+}
+
+func TestFieldMaskApply(t *testing.T) {
+	document := setupProjectDocument()
+	mask, _ := ParseFieldMask("user.name,user.profile.scores,items[1],missing")
+
+	out := mask.Apply(document)
+	expected := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name": "John",
+			"profile": map[string]interface{}{
+				"scores": []int{100, 200},
+			},
+		},
+		"items": []interface{}{
+			nil,
+			map[string]interface{}{"id": 2},
+		},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", out, expected)
+	}
+}
+
+func TestFieldMaskMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"user": map[string]interface{}{
+			"profile": map[string]interface{}{
+				"name": "John",
+				"age":  30,
+			},
+			"settings": map[string]interface{}{
+				"theme": "dark",
+				"lang":  "en",
+			},
+			"email": "john@example.com",
+		},
+	}
+	src := map[string]interface{}{
+		"user": map[string]interface{}{
+			"profile": map[string]interface{}{
+				"name": "Johnny",
+				"age":  99,
+			},
+			"settings": map[string]interface{}{
+				"theme": "light",
+			},
+			"email": "ignored@example.com",
+		},
+	}
+
+	mask, _ := ParseFieldMask("user.profile.name,user.settings,user.nickname")
+	if err := mask.Merge(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"user": map[string]interface{}{
+			"profile": map[string]interface{}{
+				"name": "Johnny",
+				"age":  30,
+			},
+			"settings": map[string]interface{}{
+				"theme": "light",
+			},
+			"email": "john@example.com",
+		},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", dst, expected)
+	}
+
+	UpdateProperty(src, "user.settings.theme", "changed")
+	if theme, _ := GetProperty(dst, "user.settings.theme"); theme != "light" {
+		t.Error("Merged values should be copies")
+	}
+
+	mask, _ = ParseFieldMask("user.settings")
+	delete(src["user"].(map[string]interface{}), "settings")
+	mask.Merge(dst, src)
+	if _, err := GetProperty(dst, "user.settings"); err == nil {
+		t.Error("Masked property missing from src should be removed")
+	}
+
+	mask, _ = ParseFieldMask("user.email.domain")
+	if err := mask.Merge(dst, map[string]interface{}{
+		"user": map[string]interface{}{"email": map[string]interface{}{"domain": "x"}},
+	}); !reflect.DeepEqual(err, fmt.Errorf("user.email: is not an object")) {
+		t.Error("Should fail on type conflict. Got ", err)
+	}
+}
+
+func TestFieldMaskUnionIntersect(t *testing.T) {
+	a, _ := ParseFieldMask("user.profile,user.email,meta")
+	b, _ := ParseFieldMask("user.profile.name,user.settings,meta.version")
+
+	union := a.Union(b)
+	if !reflect.DeepEqual(union.Paths(), []string{"meta", "user.email", "user.profile", "user.settings"}) {
+		t.Error("Unexpected union. Got ", union.Paths())
+	}
+
+	intersection := a.Intersect(b)
+	if !reflect.DeepEqual(intersection.Paths(), []string{"meta.version", "user.profile.name"}) {
+		t.Error("Unexpected intersection. Got ", intersection.Paths())
+	}
+
+	if !intersection.Contains("user.profile.name.first") || intersection.Contains("user.profile") {
+		t.Error("Contains should check path prefixes")
+	}
+
+	empty, _ := ParseFieldMask("")
+	if !empty.IsEmpty() || !a.Intersect(empty).IsEmpty() {
+		t.Error("Intersection with an empty mask should be empty")
+	}
+}
+
+func TestFieldMaskValidate(t *testing.T) {
+	document := setupProjectDocument()
+
+	mask, _ := ParseFieldMask("user.name,items[2].price,user.profile.scores[1]")
+	if err := mask.Validate(document); err != nil {
+		t.Error("Mask should be valid. Got ", err)
+	}
+
+	mask, _ = ParseFieldMask("user.name,user.unknown,items[5]")
+	if err := mask.Validate(document); !reflect.DeepEqual(err, fmt.Errorf("Property items[5] does not exist")) {
+		t.Error("Mask should be invalid. Got ", err)
+	}
+}
@@ -0,0 +1,84 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Flatten converts a nested document into a flat map keyed by paths.
+// Keys containing the separator or brackets are escaped with `\`.
+// Empty maps and arrays are kept as values so Unflatten restores them.
+// Every key can be passed to GetProperty and UpdateProperty.
+//
+//	flat := Flatten(document)      // {"one.two.three[0]": 1, ...}
+//	flat := Flatten(document, "/") // {"one/two/three[0]": 1, ...}
+func Flatten(original_data map[string]interface{}, separator_arr ...string) map[string]interface{} {
+	separator := getSeparator(separator_arr)
+
+	flat := make(map[string]interface{})
+	flattenValue(flat, Path{}, reflect.ValueOf(original_data), separator)
+	return flat
+}
+
+func flattenValue(flat map[string]interface{}, path Path, v reflect.Value, separator string) {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.IsValid() && v.Kind() == reflect.Map && isStringMap(v) && v.Len() > 0:
+		iter := v.MapRange()
+		for iter.Next() {
+			flattenValue(flat, path.Child(iter.Key().String()), iter.Value(), separator)
+		}
+		return
+	case v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() > 0:
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(flat, path.Item(i), v.Index(i), separator)
+		}
+		return
+	}
+
+	if len(path) == 0 {
+		return
+	}
+	if v.IsValid() {
+		flat[path.Format(separator)] = v.Interface()
+	} else {
+		flat[path.Format(separator)] = nil
+	}
+}
+
+// Unflatten builds a nested document from a flat map keyed by paths.
+// Missing maps and arrays are created the same way CreateProperty creates them.
+//
+//	document, err := Unflatten(map[string]interface{}{"one.two[1]": 2})
+//	// {"one": {"two": [nil, 2]}}
+func Unflatten(flat map[string]interface{}, separator_arr ...string) (map[string]interface{}, error) {
+	separator := getSeparator(separator_arr)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	original_data := make(map[string]interface{})
+	for _, key := range keys {
+		path, err := ParsePath(key, separator)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 || path[0].IsIndex {
+			return nil, fmt.Errorf(
+				"Property %s must start with a key", key,
+			)
+		}
+		// Values are copied: a key like `a.b` must not write into the value of `a`
+		if err = setPath(original_data, path, cloneValue(flat[key])); err != nil {
+			return nil, err
+		}
+	}
+	return original_data, nil
+}
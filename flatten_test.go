@@ -0,0 +1,210 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	cases := []struct {
+		in        map[string]interface{}
+		separator string
+		out       map[string]interface{}
+	}{
+		{
+			in:        setupDocument(),
+			separator: ".",
+			out: map[string]interface{}{
+				"one.two.three[0]": 1,
+				"one.two.three[1]": 2,
+				"one.two.three[2]": 3,
+				"one.four.five[0]": 11,
+				"one.four.five[1]": 22,
+				"one.four.five[2]": 33,
+			},
+		},
+		{
+			in:        setupDocument_III(),
+			separator: "/",
+			out: map[string]interface{}{
+				"request/headers/Content-Type[0]":  "application/json",
+				"request/headers/authorization[0]": "Token 1234",
+				"request/status_code":              200,
+				"request/method":                   "GET",
+			},
+		},
+		{
+			in: map[string]interface{}{
+				"api.example.com": map[string]interface{}{
+					"port": 80,
+				},
+				"matrix": [][]int{{1}, {}},
+				"empty":  map[string]interface{}{},
+				"nil":    nil,
+			},
+			separator: ".",
+			out: map[string]interface{}{
+				`api\.example\.com.port`: 80,
+				"matrix[0][0]":           1,
+				"matrix[1]":              []int{},
+				"empty":                  map[string]interface{}{},
+				"nil":                    nil,
+			},
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		out := Flatten(c.in, c.separator)
+		if !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	cases := []struct {
+		in        map[string]interface{}
+		separator string
+		out       map[string]interface{}
+		err       error
+	}{
+		{
+			in: map[string]interface{}{
+				"user.profile.scores[1]": 100,
+				"user.profile.name":      "John",
+			},
+			separator: ".",
+			out: map[string]interface{}{
+				"user": map[string]interface{}{
+					"profile": map[string]interface{}{
+						"scores": []interface{}{nil, 100},
+						"name":   "John",
+					},
+				},
+			},
+		},
+		{
+			in: map[string]interface{}{
+				"a::b.c": 1,
+			},
+			separator: "::",
+			out: map[string]interface{}{
+				"a": map[string]interface{}{
+					"b.c": 1,
+				},
+			},
+		},
+		{
+			in: map[string]interface{}{
+				"a":   1,
+				"a.b": 2,
+			},
+			separator: ".",
+			err:       fmt.Errorf("a: is not an object"),
+		},
+		{
+			in: map[string]interface{}{
+				"a.b":    1,
+				"a.b[0]": 2,
+			},
+			separator: ".",
+			err:       fmt.Errorf("a.b: is not an array"),
+		},
+		{
+			in: map[string]interface{}{
+				"a":   map[string]interface{}{"c": 1},
+				"a.b": 2,
+			},
+			separator: ".",
+			out: map[string]interface{}{
+				"a": map[string]interface{}{"b": 2, "c": 1},
+			},
+		},
+		{
+			in: map[string]interface{}{
+				"[0]": 1,
+			},
+			separator: ".",
+			err:       fmt.Errorf("Property [0] must start with a key"),
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		in := Clone(c.in)
+		out, err := Unflatten(c.in, c.separator)
+		if !reflect.DeepEqual(c.in, in) {
+			t.Errorf("\n[%d of %d: Input should not change] \n\t%v \n \n\t%v", case_index, num_cases, c.in, in)
+		}
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err, c.err)
+		}
+		if !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	documents := []map[string]interface{}{
+		setupDocument(),
+		setupDocument_I(),
+		setupDocument_II(),
+		setupDocument_III(),
+		{
+			"servers": map[string]interface{}{
+				"api.example.com": map[string]interface{}{
+					"tags[0]": []interface{}{"a", nil, map[string]interface{}{}},
+				},
+				`back\slash`: [][]interface{}{{1, 2}, {}},
+			},
+		},
+	}
+
+	for _, separator := range []string{".", "/", "::"} {
+		for i, document := range documents {
+			out, err := Unflatten(Flatten(document, separator), separator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(out, document) {
+				t.Errorf("\n[%d, %s: Round trip should equal] \n\t%v \n \n\t%v", i+1, separator, out, document)
+			}
+		}
+	}
+}
+
+func TestFlattenKeysResolve(t *testing.T) {
+	document := map[string]interface{}{
+		"servers": map[string]interface{}{
+			"api.example.com": map[string]interface{}{"port": 80},
+			"a/b":             []interface{}{1},
+		},
+		"matrix":     [][]interface{}{{1, 2}, {3}},
+		"user list":  []interface{}{"a", map[string]interface{}{"tags[0]": true}},
+		`back\slash`: "x",
+	}
+
+	for _, separator := range []string{".", "/", "::"} {
+		flat := Flatten(document, separator)
+		updated := Clone(document)
+		for key, value := range flat {
+			found, err := GetProperty(document, key, separator)
+			if err != nil || !Equal(found, value) {
+				t.Errorf("\n[%s: %s] GetProperty should return \n\t%v \n \n\t%v %v", separator, key, value, found, err)
+			}
+			if err := UpdateProperty(updated, key, value, separator); err != nil {
+				t.Errorf("\n[%s: %s] UpdateProperty should not fail: %v", separator, key, err)
+			}
+		}
+		if !Equal(updated, document) {
+			t.Errorf("\n[%s] Updating every key should not change the document \n\t%v \n \n\t%v", separator, updated, document)
+		}
+	}
+}
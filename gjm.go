@@ -13,126 +13,261 @@ import (
 //	property, err := GetProperty(document, "one.two.three[0]")
 //	property, err := GetProperty(document, "one.two.three[0]", ".")
 //	property, err := GetProperty(document, "one/two/three[0]", "/")
+//	property, err := GetProperty(document, `servers.api\.example\.com.matrix[0][1]`)
 //
-// Property type is `interface{}`
+// Paths follow the grammar of ParsePath: keys containing the separator or
+// brackets are escaped with `\` and several indexes can follow a key.
+// Property type is `interface{}`. Maps and arrays are returned as they are
+// stored, without copying. Reads do not allocate for documents made of
+// `map[string]interface{}` and `[]interface{}`, like the ones json.Unmarshal returns.
 func GetProperty(original_data map[string]interface{}, path string, separator_arr ...string) (path_parsed interface{}, err error) {
-	var separator = "."
-	if len(separator_arr) > 0 {
-		if len(separator_arr[0]) > 0 {
-			separator = separator_arr[0]
+	return getProperty(original_data, path, getSeparator(separator_arr))
+}
+
+// getProperty reads a property of a `map[string]interface{}`, a mapObject or an *OrderedMap.
+func getProperty(original_data interface{}, path string, separator string) (interface{}, error) {
+	// The whole document is returned as a shallow copy
+	if len(path) == 0 || path == separator {
+		switch d := original_data.(type) {
+		case *OrderedMap:
+			data := NewOrderedMap()
+			for _, key := range d.keys {
+				data.Set(key, d.values[key])
+			}
+			return data, nil
+		case mapObject:
+			original_data = map[string]interface{}(d)
+		}
+		document := original_data.(map[string]interface{})
+		data := make(map[string]interface{}, len(document))
+		for key, value := range document {
+			data[key] = value
+		}
+		return data, nil
+	}
+
+	// Escaped keys and levels like `matrix[0][1]`
+	if usesPathGrammar(path, separator) {
+		parsed, err := parsePath(path, separator, false)
+		if err != nil {
+			return nil, err
 		}
+		return getPathProperty(original_data, parsed, separator)
 	}
 
-	data := make(map[string]interface{})
-	d := reflect.ValueOf(original_data)
-	for _, key := range d.MapKeys() {
-		data[key.String()] = d.MapIndex(key).Interface()
+	level, next := nextLevel(path, separator, 0)
+	if len(level) == 0 {
+		return nil, fmt.Errorf("Property %s does not exist", path)
 	}
 
-	err = fmt.Errorf("Property %s does not exist", path)
+	// Maps are indexed in place: the common map[string]interface{} directly,
+	// typed maps with reflect
+	data := original_data
+	start := 0
+	for {
+		following, after := nextLevel(path, separator, next)
 
-	if len(path) == 0 {
-		path = separator
+		property := level
+		var value interface{}
+		var ok bool
+
+		// Levels like `property[index]` select an element of an array
+		if indexed_property, index_found, indexed := parseIndexLevel(level); !indexed {
+			value, ok = lookupKey(data, property)
+		} else {
+			property = indexed_property
+
+			index, err := strconv.Atoi(index_found)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", property, index_found),
+					"number",
+				)
+			}
+			if value, ok = lookupKey(data, property); !ok {
+				return nil, fmt.Errorf(
+					"Property %s does not exist", property,
+				)
+			}
+			if value, err = indexSlice(value, property, index); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(following) == 0 {
+			if !ok {
+				return nil, fmt.Errorf("Property %s does not exist", remainingPath(path, separator, start))
+			}
+			return value, nil
+		}
+
+		if !ok {
+			return nil, fmt.Errorf(
+				"Property %s does not exist", property,
+			)
+		}
+		if !isObject(value) {
+			return nil, fmt.Errorf("Property %s does not exist", remainingPath(path, separator, start))
+		}
+
+		data = value
+		start = next
+		level, next = following, after
 	}
+}
 
-	levels_tmp := strings.Split(path, separator)
-	levels := make([]string, 0)
-	for _, level_tmp := range levels_tmp {
-		if len(level_tmp) > 0 {
-			levels = append(levels, level_tmp)
+// parseIndexLevel splits a level like `property[index]`. It gives the same
+// results as matching the level with `(\w+[\_]?[\-]?)+\[\d+\]{1}`, then
+// taking the first `(\w+[\_]?[\-]?)+` as the property and the digits of the
+// first `\[\d+\]{1}` as the index, without the cost of regular expressions.
+func parseIndexLevel(level string) (property string, index_found string, ok bool) {
+	if strings.IndexByte(level, '[') < 0 {
+		return "", "", false
+	}
+
+	for i := 0; i < len(level); i++ {
+		if level[i] != '[' {
+			continue
+		}
+		end := i + 1
+		for end < len(level) && level[end] >= '0' && level[end] <= '9' {
+			end++
+		}
+		if end == i+1 || end == len(level) || level[end] != ']' {
+			continue
+		}
+		if len(index_found) == 0 {
+			index_found = level[i+1 : end]
+		}
+		if i > 0 && (isWordChar(level[i-1]) || (level[i-1] == '-' && i > 1 && isWordChar(level[i-2]))) {
+			ok = true
+			break
 		}
 	}
+	if !ok {
+		return "", "", false
+	}
 
-	if len(levels) > 0 && path != separator {
-		path_level_one := levels[0]
-		// If we have a level in path_level_one
+	start := 0
+	for start < len(level) && !isWordChar(level[start]) {
+		start++
+	}
+	end := start
+	for end < len(level) && isWordChar(level[end]) {
+		end++
+		for end < len(level) && isWordChar(level[end]) {
+			end++
+		}
+		if end < len(level) && level[end] == '-' {
+			end++
+		}
+	}
+	return level[start:end], index_found, true
+}
 
-		re := regexp.MustCompile(`(\w+[\_]?[\-]?)+\[\d+\]{1}`)
-		if matched := re.FindString(path_level_one); len(matched) > 0 {
-			property_re := regexp.MustCompile(`(\w+[\_]?[\-]?)+`)
-			index_re := regexp.MustCompile(`\[\d+\]{1}`)
-			// Get a property
-			// avatars
-			property := property_re.FindString(path_level_one)
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
 
-			// Get an index
-			index_found := index_re.FindString(path_level_one)
+// nextLevel returns the first non-empty level of path at or after start
+// and the position following it.
+func nextLevel(path string, separator string, start int) (string, int) {
+	for start < len(path) {
+		end := strings.Index(path[start:], separator)
+		if end < 0 {
+			return path[start:], len(path)
+		}
+		if end > 0 {
+			return path[start : start+end], start + end + len(separator)
+		}
+		start += len(separator)
+	}
+	return "", len(path)
+}
 
-			// If index > 0 - check if this property is array
-			if len(index_found) > 0 {
-				if len(property) > 0 {
-					path_level_one = property
-				}
-				index_found = strings.Trim(index_found, "[]")
-				if index, err := strconv.Atoi(index_found); err == nil {
-					if v, ok := data[property]; ok {
-						if isKind(v, reflect.Slice) {
-							slice := reflect.ValueOf(v)
-							if index >= 0 && index < slice.Len() {
-								value := slice.Index(index).Interface()
+// remainingPath returns the path starting at the level at start.
+// Nested levels are reported without empty levels.
+func remainingPath(path string, separator string, start int) string {
+	if start == 0 {
+		return path
+	}
+	levels := make([]string, 0)
+	for level, next := nextLevel(path, separator, start); len(level) > 0; level, next = nextLevel(path, separator, next) {
+		levels = append(levels, level)
+	}
+	return strings.Join(levels, separator)
+}
 
-								data[property] = value
-							} else {
-								err = fmt.Errorf(
-									"%s: Min index is 0, Max index is %d. You passed index %d", property, slice.Len(), index,
-								)
-								return path_parsed, err
-							}
-						} else {
-							err = fmt.Errorf(
-								"%s: is not an array", property,
-							)
-							return path_parsed, err
-						}
-					} else {
-						err = fmt.Errorf(
-							"Property %s does not exist", property,
-						)
-						return path_parsed, err
-					}
-				} else {
-					err = fmt.Errorf(
-						"%s must be of type %s",
-						fmt.Sprintf("%s[%s]", property, index_found),
-						"number",
-					)
-					return path_parsed, err
-				}
-			}
+// lookupKey returns a value of a map. Keys of typed maps are compared
+// with the string representation of their reflect.Value.
+func lookupKey(data interface{}, key string) (interface{}, bool) {
+	switch generic := data.(type) {
+	case map[string]interface{}:
+		value, ok := generic[key]
+		return value, ok
+	case mapObject:
+		value, ok := generic[key]
+		return value, ok
+	case *OrderedMap:
+		if generic == nil {
+			return nil, false
 		}
+		return generic.Get(key)
+	}
 
-		if len(levels[1:]) >= 1 {
-			if level_one_value, ok := data[path_level_one]; ok {
-				if level_one_value != nil {
-					switch reflect.TypeOf(level_one_value).Kind() {
-					case reflect.Map:
-						mapped_level_one_value := make(map[string]interface{})
-						d := reflect.ValueOf(level_one_value)
-						for _, key := range d.MapKeys() {
-							mapped_level_one_value[key.String()] = d.MapIndex(key).Interface()
-						}
-						return GetProperty(mapped_level_one_value, strings.Join(levels[1:], separator), separator)
-					default:
-						// pass
-					}
-				}
-			} else {
-				err = fmt.Errorf(
-					"Property %s does not exist", path_level_one,
-				)
-				return path_parsed, err
-			}
-		} else {
-			if v, ok := data[path_level_one]; ok {
-				path_parsed = v
-				err = nil
-			}
+	d := reflect.ValueOf(data)
+	if d.Kind() != reflect.Map {
+		return nil, false
+	}
+	if d.Type().Key().Kind() == reflect.String {
+		value := d.MapIndex(reflect.ValueOf(key).Convert(d.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
 		}
-	} else if path == separator {
-		path_parsed = data
-		err = nil
+		return value.Interface(), true
 	}
-	return
+	iter := d.MapRange()
+	for iter.Next() {
+		if iter.Key().String() == key {
+			return iter.Value().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// isObject reports whether a value is a map or a non-nil *OrderedMap
+// which properties can be read.
+func isObject(value interface{}) bool {
+	if ordered, ok := value.(*OrderedMap); ok {
+		return ordered != nil
+	}
+	return value != nil && reflect.TypeOf(value).Kind() == reflect.Map
+}
+
+// indexSlice returns an element of a slice.
+func indexSlice(value interface{}, property string, index int) (interface{}, error) {
+	if generic, ok := value.([]interface{}); ok {
+		if index >= 0 && index < len(generic) {
+			return generic[index], nil
+		}
+		return nil, fmt.Errorf(
+			"%s: Min index is 0, Max index is %d. You passed index %d", property, len(generic), index,
+		)
+	}
+
+	if !isKind(value, reflect.Slice) {
+		return nil, fmt.Errorf(
+			"%s: is not an array", property,
+		)
+	}
+	slice := reflect.ValueOf(value)
+	if index >= 0 && index < slice.Len() {
+		return slice.Index(index).Interface(), nil
+	}
+	return nil, fmt.Errorf(
+		"%s: Min index is 0, Max index is %d. You passed index %d", property, slice.Len(), index,
+	)
 }
 
 // DeleteProperty removes a property from map
@@ -141,15 +276,20 @@ func GetProperty(original_data map[string]interface{}, path string, separator_ar
 //	err := DeleteProperty(document, "one.two.three[0]", ".")
 //	err := DeleteProperty(document, "one/two/three[0]", "/")
 func DeleteProperty(original_data map[string]interface{}, path string, separator_arr ...string) (err error) {
-	var separator = "."
-	if len(separator_arr) > 0 {
-		if len(separator_arr[0]) > 0 {
-			separator = separator_arr[0]
+	return deleteProperty(mapObject(original_data), path, getSeparator(separator_arr))
+}
+
+func deleteProperty(original_data object, path string, separator string) (err error) {
+	if usesPathGrammar(path, separator) {
+		parsed, err := parsePath(path, separator, false)
+		if err != nil {
+			return err
 		}
+		return deletePathProperty(original_data, parsed, separator)
 	}
 
 	// If we have a property
-	if _, err = GetProperty(original_data, path, separator); err != nil {
+	if _, err = getProperty(original_data, path, separator); err != nil {
 		return
 	}
 
@@ -188,26 +328,25 @@ func DeleteProperty(original_data map[string]interface{}, path string, separator
 				}
 				index_found = strings.Trim(index_found, "[]")
 				if index, err := strconv.Atoi(index_found); err == nil {
-					if v, ok := original_data[property]; ok {
+					if v, ok := original_data.get(property); ok {
 						if isKind(v, reflect.Slice) {
 							slice := reflect.ValueOf(v)
 							if index >= 0 && index < slice.Len() {
 								value := slice.Index(index).Interface()
 								// If len of other levels greater than 0
 								if len(levels[1:]) >= 1 {
-									if isKind(value, reflect.Map) {
-										mapped_value := value.(map[string]interface{})
-										err = DeleteProperty(mapped_value, strings.Join(levels[1:], separator), separator)
+									if mapped_value, ok := asObject(value); ok {
+										err = deleteProperty(mapped_value, strings.Join(levels[1:], separator), separator)
 										if err == nil {
 											// If we have an empty value inside of a slice - remove it
-											if len(mapped_value) == 0 {
+											if mapped_value.len() == 0 {
 												slices := make([]interface{}, 0)
 												for i := 0; i < slice.Len(); i++ {
 													if i != index {
 														slices = append(slices, slice.Index(i).Interface())
 													}
 												}
-												original_data[property] = slices
+												original_data.set(property, slices)
 											}
 										}
 										return err
@@ -220,7 +359,7 @@ func DeleteProperty(original_data map[string]interface{}, path string, separator
 											slices = append(slices, slice.Index(i).Interface())
 										}
 									}
-									original_data[property] = slices
+									original_data.set(property, slices)
 									return err
 								}
 							} else {
@@ -252,18 +391,15 @@ func DeleteProperty(original_data map[string]interface{}, path string, separator
 		}
 
 		if len(levels[1:]) >= 1 {
-			if level_one_value, ok := original_data[path_level_one]; ok {
+			if level_one_value, ok := original_data.get(path_level_one); ok {
 				if level_one_value != nil {
-					switch reflect.TypeOf(level_one_value).Kind() {
-					case reflect.Map:
-						if mapped_level_one_value, ok := level_one_value.(map[string]interface{}); ok {
-							err = DeleteProperty(mapped_level_one_value, strings.Join(levels[1:], separator), separator)
-							if err != nil {
-								return
-							}
+					if mapped_level_one_value, ok := asObject(level_one_value); ok {
+						err = deleteProperty(mapped_level_one_value, strings.Join(levels[1:], separator), separator)
+						if err != nil {
+							return
 						}
-					default:
-						delete(original_data, path)
+					} else if reflect.TypeOf(level_one_value).Kind() != reflect.Map {
+						original_data.del(path)
 					}
 
 				}
@@ -274,12 +410,10 @@ func DeleteProperty(original_data map[string]interface{}, path string, separator
 				return
 			}
 		} else {
-			delete(original_data, path_level_one)
+			original_data.del(path_level_one)
 		}
 	} else if path == separator {
-		for k := range original_data {
-			delete(original_data, k)
-		}
+		original_data.clear()
 	}
 
 	return
@@ -291,16 +425,29 @@ func DeleteProperty(original_data map[string]interface{}, path string, separator
 //	err := CreateProperty(document, "one.two.three[0]", "string value")
 //	err := CreateProperty(document, "one.two.three[0]", "string value", ".")
 //	err := CreateProperty(document, "one/two/three[0]", "string value", "/")
+//	err := CreateProperty(document, "one.two.three[+]", "appended value")
 func CreateProperty(original_data map[string]interface{}, path string, value interface{}, separator_arr ...string) (err error) {
-	var separator = "."
-	if len(separator_arr) > 0 {
-		if len(separator_arr[0]) > 0 {
-			separator = separator_arr[0]
+	return createProperty(mapObject(original_data), path, value, getSeparator(separator_arr))
+}
+
+func createProperty(original_data object, path string, value interface{}, separator string) (err error) {
+	if usesPathGrammar(path, separator) {
+		parsed, err := parsePath(path, separator, true)
+		if err != nil {
+			return err
+		}
+		if _, err = getPathProperty(original_data, parsed, separator); err == nil {
+			return fmt.Errorf(
+				"Property %s already exists", path,
+			)
 		}
+		return setPathProperty(original_data, parsed, value, separator)
 	}
 
+	path = resolveAppendTokens(original_data, path, separator)
+
 	// If we have a property - raise an error
-	if _, err = GetProperty(original_data, path, separator); err == nil {
+	if _, err = getProperty(original_data, path, separator); err == nil {
 		err = fmt.Errorf(
 			"Property %s already exists", path,
 		)
@@ -343,7 +490,7 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 				}
 				index_found = strings.Trim(index_found, "[]")
 				if index, err := strconv.Atoi(index_found); err == nil {
-					if v, ok := original_data[property]; ok {
+					if v, ok := original_data.get(property); ok {
 						if isKind(v, reflect.Slice) {
 							slice := reflect.ValueOf(v)
 							var dest_value interface{}
@@ -354,9 +501,8 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 
 							// If len of other levels greater than 0
 							if len(levels[1:]) >= 1 {
-								if isKind(dest_value, reflect.Map) {
-									mapped_value := dest_value.(map[string]interface{})
-									return CreateProperty(mapped_value, strings.Join(levels[1:], separator), value, separator)
+								if mapped_value, ok := asObject(dest_value); ok {
+									return createProperty(mapped_value, strings.Join(levels[1:], separator), value, separator)
 								} else if dest_value == nil {
 									slice_len := slice.Len()
 									if index > slice_len-1 {
@@ -372,16 +518,16 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 										vv[i] = val
 									}
 
-									mapped_value := make(map[string]interface{})
+									mapped_value, new_value := newObject(original_data)
 
-									err = CreateProperty(
+									err = createProperty(
 										mapped_value,
 										strings.Join(levels[1:], separator),
 										value,
 										separator,
 									)
-									vv[index] = mapped_value
-									original_data[property] = vv
+									vv[index] = new_value
+									original_data.set(property, vv)
 
 									return err
 								}
@@ -400,7 +546,7 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 								}
 								slices[index] = value
 
-								original_data[path_level_one] = slices
+								original_data.set(path_level_one, slices)
 								return err
 							}
 						} else {
@@ -410,10 +556,10 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 						}
 					} else {
 						new_sliced_value := make([]interface{}, index+1)
-						new_mapped_value := make(map[string]interface{})
+						_, new_mapped_value := newObject(original_data)
 						new_sliced_value[index] = new_mapped_value
-						original_data[path_level_one] = new_sliced_value
-						return UpdateProperty(original_data, path, value, separator)
+						original_data.set(path_level_one, new_sliced_value)
+						return updateProperty(original_data, path, value, separator)
 					}
 				} else {
 					err = fmt.Errorf(
@@ -430,31 +576,28 @@ func CreateProperty(original_data map[string]interface{}, path string, value int
 		}
 
 		if len(levels[1:]) >= 1 {
-			if level_one_value, ok := original_data[path_level_one]; ok {
+			if level_one_value, ok := original_data.get(path_level_one); ok {
 				if level_one_value != nil {
-					switch reflect.TypeOf(level_one_value).Kind() {
-					case reflect.Map:
-						if mapped_level_one_value, ok := level_one_value.(map[string]interface{}); ok {
-							return CreateProperty(mapped_level_one_value, strings.Join(levels[1:], separator), value, separator)
-						}
-					default:
-						original_data[path] = value
+					if mapped_level_one_value, ok := asObject(level_one_value); ok {
+						return createProperty(mapped_level_one_value, strings.Join(levels[1:], separator), value, separator)
+					} else if reflect.TypeOf(level_one_value).Kind() != reflect.Map {
+						original_data.set(path, value)
 					}
 
 				}
 			} else {
-				new_mapped_value := make(map[string]interface{})
-				original_data[path_level_one] = new_mapped_value
-				return CreateProperty(new_mapped_value, strings.Join(levels[1:], separator), value, separator)
+				new_mapped_value, new_value := newObject(original_data)
+				original_data.set(path_level_one, new_value)
+				return createProperty(new_mapped_value, strings.Join(levels[1:], separator), value, separator)
 			}
 		} else {
 			// If a map does not contain a last node property
-			if _, ok := original_data[path_level_one]; !ok {
-				original_data[path_level_one] = value
+			if _, ok := original_data.get(path_level_one); !ok {
+				original_data.set(path_level_one, value)
 			}
 		}
 	} else if path == separator {
-		original_data[path] = value
+		original_data.set(path, value)
 	}
 	return
 }
@@ -471,17 +614,27 @@ func AddProperty(original_data map[string]interface{}, path string, value interf
 //	err := UpdateProperty(document, "one.two.three[0]", "string value")
 //	err := UpdateProperty(document, "one.two.three[0]", "string value", ".")
 //	err := UpdateProperty(document, "one/two/three[0]", "string value", "/")
+//	err := UpdateProperty(document, "one.two.three[+]", "appended value")
+//
+// `[+]` and `[-]` in place of an index append to the array.
 func UpdateProperty(original_data map[string]interface{}, path string, value interface{}, separator_arr ...string) (err error) {
-	var separator = "."
-	if len(separator_arr) > 0 {
-		if len(separator_arr[0]) > 0 {
-			separator = separator_arr[0]
+	return updateProperty(mapObject(original_data), path, value, getSeparator(separator_arr))
+}
+
+func updateProperty(original_data object, path string, value interface{}, separator string) (err error) {
+	if usesPathGrammar(path, separator) {
+		parsed, err := parsePath(path, separator, true)
+		if err != nil {
+			return err
 		}
+		return setPathProperty(original_data, parsed, value, separator)
 	}
 
+	path = resolveAppendTokens(original_data, path, separator)
+
 	// If we have a property - update it, otherwise add it
-	if _, err = GetProperty(original_data, path, separator); err != nil {
-		return AddProperty(original_data, path, value, separator)
+	if _, err = getProperty(original_data, path, separator); err != nil {
+		return createProperty(original_data, path, value, separator)
 	} else {
 		if len(path) == 0 {
 			path = separator
@@ -515,7 +668,7 @@ func UpdateProperty(original_data map[string]interface{}, path string, value int
 					}
 					index_found = strings.Trim(index_found, "[]")
 					if index, err := strconv.Atoi(index_found); err == nil {
-						if v, ok := original_data[property]; ok {
+						if v, ok := original_data.get(property); ok {
 							if isKind(v, reflect.Slice) {
 								slice := reflect.ValueOf(v)
 								var dest_value interface{}
@@ -524,9 +677,8 @@ func UpdateProperty(original_data map[string]interface{}, path string, value int
 								}
 								// If len of other levels greater than 0
 								if len(levels[1:]) >= 1 {
-									if isKind(dest_value, reflect.Map) {
-										mapped_value := dest_value.(map[string]interface{})
-										return UpdateProperty(mapped_value, strings.Join(levels[1:], separator), value, separator)
+									if mapped_value, ok := asObject(dest_value); ok {
+										return updateProperty(mapped_value, strings.Join(levels[1:], separator), value, separator)
 									}
 								} else {
 									// if this is a `property[1]` in a path like `path.to.property[1]`
@@ -542,7 +694,7 @@ func UpdateProperty(original_data map[string]interface{}, path string, value int
 										slices = append(slices, value)
 									}
 
-									original_data[path_level_one] = slices
+									original_data.set(path_level_one, slices)
 									return err
 								}
 							}
@@ -566,15 +718,12 @@ func UpdateProperty(original_data map[string]interface{}, path string, value int
 			}
 
 			if len(levels[1:]) >= 1 {
-				if level_one_value, ok := original_data[path_level_one]; ok {
+				if level_one_value, ok := original_data.get(path_level_one); ok {
 					if level_one_value != nil {
-						switch reflect.TypeOf(level_one_value).Kind() {
-						case reflect.Map:
-							if mapped_level_one_value, ok := level_one_value.(map[string]interface{}); ok {
-								return UpdateProperty(mapped_level_one_value, strings.Join(levels[1:], separator), value, separator)
-							}
-						default:
-							original_data[path] = value
+						if mapped_level_one_value, ok := asObject(level_one_value); ok {
+							return updateProperty(mapped_level_one_value, strings.Join(levels[1:], separator), value, separator)
+						} else if reflect.TypeOf(level_one_value).Kind() != reflect.Map {
+							original_data.set(path, value)
 						}
 					}
 				} else {
@@ -584,10 +733,10 @@ func UpdateProperty(original_data map[string]interface{}, path string, value int
 					return
 				}
 			} else {
-				original_data[path_level_one] = value
+				original_data.set(path_level_one, value)
 			}
 		} else if path == separator {
-			original_data[path] = value
+			original_data.set(path, value)
 		}
 	}
 
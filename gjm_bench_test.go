@@ -0,0 +1,68 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func setupBenchmarkDocument() map[string]interface{} {
+	document := make(map[string]interface{})
+	json.Unmarshal([]byte(`{
+		"event": {
+			"type": "push",
+			"repository": {"name": "go-json-map", "owner": {"login": "firewut"}},
+			"commits": [
+				{"id": "a1", "author": {"name": "John"}},
+				{"id": "b2", "author": {"name": "Jane"}}
+			]
+		}
+	}`), &document)
+	for i := 0; i < 100; i++ {
+		document[fmt.Sprintf("field_%d", i)] = i
+		document["event"].(map[string]interface{})[fmt.Sprintf("field_%d", i)] = i
+	}
+	return document
+}
+
+func TestGetPropertyAllocations(t *testing.T) {
+	document := setupBenchmarkDocument()
+	paths := []string{
+		"event.repository.owner.login",
+		"event.commits[1].author.name",
+	}
+	for _, path := range paths {
+		allocations := testing.AllocsPerRun(100, func() {
+			GetProperty(document, path)
+		})
+		if allocations > 0 {
+			t.Errorf("GetProperty(%s) should not allocate. Got %v allocations", path, allocations)
+		}
+	}
+}
+
+func BenchmarkGetProperty(b *testing.B) {
+	document := setupBenchmarkDocument()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetProperty(document, "event.repository.owner.login")
+	}
+}
+
+func BenchmarkGetPropertyIndex(b *testing.B) {
+	document := setupBenchmarkDocument()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetProperty(document, "event.commits[1].author.name")
+	}
+}
+
+func BenchmarkGetPropertyTypedMap(b *testing.B) {
+	document := map[string]interface{}{
+		"labels": map[string]string{"env": "production"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetProperty(document, "labels.env")
+	}
+}
@@ -2,8 +2,30 @@ package gjm
 
 import (
 	"reflect"
+	"strings"
 )
 
 func isKind(what interface{}, kind reflect.Kind) bool {
 	return reflect.ValueOf(what).Kind() == kind
 }
+
+// getSeparator returns the first non-empty separator or the default ".".
+func getSeparator(separator_arr []string) string {
+	if len(separator_arr) > 0 {
+		if len(separator_arr[0]) > 0 {
+			return separator_arr[0]
+		}
+	}
+	return "."
+}
+
+// splitLevels splits a path by separator dropping empty levels.
+func splitLevels(path string, separator string) []string {
+	levels := make([]string, 0)
+	for _, level := range strings.Split(path, separator) {
+		if len(level) > 0 {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
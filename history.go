@@ -0,0 +1,365 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DocumentOption configures a Document, see NewDocument.
+type DocumentOption func(d *Document)
+
+// WithHistory keeps the last size changes of a Document, so they can be
+// undone and redone and earlier versions can be read back.
+// Every successful Update is one change, whatever number of properties it sets.
+//
+//	doc := NewDocument(config, WithHistory(100))
+//	doc.UpdateProperty("database.port", 5433)
+//	doc.Undo()
+func WithHistory(size int) DocumentOption {
+	return func(d *Document) {
+		if size > 0 {
+			d.history = &history{size: size}
+		}
+	}
+}
+
+// PatchOperation is a JSON Patch (RFC 6902) operation.
+// Operations are built from the changed values, so they apply to the
+// document as it was: a property created with its parents is one `add`
+// of the first missing parent, and an array padded with nil values or
+// changed in more than one place is replaced.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the operation keeping null values of `add` and `replace`.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(map[string]interface{}{"op": o.Op, "path": o.Path})
+	}
+	return json.Marshal(map[string]interface{}{"op": o.Op, "path": o.Path, "value": o.Value})
+}
+
+type history struct {
+	size    int
+	entries []historyEntry
+	// number of entries applied to the document
+	applied int
+}
+
+// historyEntry holds the content of the maps changed by an Update
+// before and after the Update.
+type historyEntry struct {
+	before     []undoEntry
+	after      []undoEntry
+	region     Path
+	operations []PatchOperation
+}
+
+// Version returns the number of changes applied to the document.
+// Undo decreases it and Redo increases it.
+func (d *Document) Version() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.version
+}
+
+// Undo reverts the last change.
+func (d *Document) Undo() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.history == nil {
+		return fmt.Errorf("Document has no history")
+	}
+	if d.history.applied == 0 {
+		return fmt.Errorf("Nothing to undo")
+	}
+
+	entry := d.history.entries[d.history.applied-1]
+	events, _ := d.observeRegion(entry.region, func() error {
+		d.undo()
+		return nil
+	})
+	d.deliver(events)
+	return nil
+}
+
+// Redo applies again the last change reverted by Undo.
+// Any other change discards the changes which can be redone.
+func (d *Document) Redo() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.history == nil {
+		return fmt.Errorf("Document has no history")
+	}
+	if d.history.applied == len(d.history.entries) {
+		return fmt.Errorf("Nothing to redo")
+	}
+
+	entry := d.history.entries[d.history.applied]
+	events, _ := d.observeRegion(entry.region, func() error {
+		d.redo()
+		return nil
+	})
+	d.deliver(events)
+	return nil
+}
+
+// Snapshot returns a deep copy of the document as it was at a version.
+// Versions from the oldest change kept in history to the last undone change are available.
+func (d *Document) Snapshot(version int) (map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if version == d.version {
+		return Clone(d.data), nil
+	}
+	if d.history == nil {
+		return nil, fmt.Errorf("Version %d is not in history", version)
+	}
+	oldest := d.version - d.history.applied
+	if version < oldest || version > oldest+len(d.history.entries) {
+		return nil, fmt.Errorf("Version %d is not in history", version)
+	}
+
+	current := d.version
+	for d.version > version {
+		d.undo()
+	}
+	for d.version < version {
+		d.redo()
+	}
+	snapshot := Clone(d.data)
+	for d.version > current {
+		d.undo()
+	}
+	for d.version < current {
+		d.redo()
+	}
+	return snapshot, nil
+}
+
+// History returns the changes kept in history up to the current version
+// as a JSON Patch sequence.
+func (d *Document) History() []PatchOperation {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	operations := make([]PatchOperation, 0)
+	if d.history == nil {
+		return operations
+	}
+	for _, entry := range d.history.entries[:d.history.applied] {
+		for _, operation := range entry.operations {
+			operation.Value = cloneValue(operation.Value)
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// undo reverts the last applied change. Must be called holding the write lock.
+func (d *Document) undo() {
+	d.history.applied--
+	d.version--
+	restoreUndo(d.history.entries[d.history.applied].before)
+}
+
+// redo applies the first reverted change. Must be called holding the write lock.
+func (d *Document) redo() {
+	restoreUndo(d.history.entries[d.history.applied].after)
+	d.history.applied++
+	d.version++
+}
+
+// commit adds the changes of a committed transaction to the history,
+// discarding the changes which could be redone. Must be called holding the write lock.
+func (h *history) commit(before []undoEntry, operations []PatchOperation, regions []Path) {
+	// the content every changed map has after the transaction
+	after := make([]undoEntry, 0)
+	seen := make(map[uintptr]bool)
+	for _, entry := range before {
+		pointer := reflect.ValueOf(entry.data).Pointer()
+		if seen[pointer] {
+			continue
+		}
+		seen[pointer] = true
+		after = append(after, newUndoEntry(entry.data))
+	}
+
+	h.entries = append(h.entries[:h.applied], historyEntry{
+		before:     before,
+		after:      after,
+		region:     commonPrefix(regions),
+		operations: operations,
+	})
+	for len(h.entries) > h.size {
+		h.entries[0] = historyEntry{}
+		h.entries = h.entries[1:]
+	}
+	h.applied = len(h.entries)
+}
+
+// patchRecorder describes the change a CRUD function makes to a path
+// comparing the changed region before and after the change.
+type patchRecorder struct {
+	// target is the shortest path of the region missing before the change,
+	// or the region itself
+	target  Path
+	existed bool
+	// before is a copy of an array region, compared element by element
+	before interface{}
+}
+
+// newPatchRecorder records the state of the region a change of path may
+// modify. It must be called before the change.
+func newPatchRecorder(original_data interface{}, path string, separator string) *patchRecorder {
+	region := changedRegion(path, separator)
+	for i := range region {
+		if _, err := getPathProperty(original_data, region[:i+1], separator); err != nil {
+			return &patchRecorder{target: region[:i+1]}
+		}
+	}
+
+	recorder := &patchRecorder{target: region, existed: true}
+	if parsed, err := parsePath(path, separator, true); err != nil || len(parsed) > len(region) {
+		value, _ := getPathProperty(original_data, region, separator)
+		recorder.before = cloneValue(value)
+	}
+	return recorder
+}
+
+// operations returns the JSON Patch operations which change the recorded
+// region into its value in original_data. A region missing before the
+// change is added whole, so parents created by the change are added too.
+func (r *patchRecorder) operations(original_data interface{}, separator string) []PatchOperation {
+	pointer := jsonPointer(r.target)
+	value, err := getPathProperty(original_data, r.target, separator)
+	switch {
+	case err != nil && r.existed:
+		return []PatchOperation{{Op: "remove", Path: pointer}}
+	case err != nil:
+		return nil
+	case !r.existed:
+		return []PatchOperation{{Op: "add", Path: pointer, Value: cloneValue(value)}}
+	case r.before != nil:
+		return diffPatch(pointer, r.before, value)
+	}
+	return []PatchOperation{{Op: "replace", Path: pointer, Value: cloneValue(value)}}
+}
+
+// diffPatch returns the operations which change before into after at pointer.
+// Objects are compared key by key and arrays element by element. An array
+// which gained or lost one element gets one `add` or `remove`, any other
+// change of its length replaces the array.
+func diffPatch(pointer string, before interface{}, after interface{}) []PatchOperation {
+	if Equal(before, after) {
+		return nil
+	}
+
+	if before_object, ok := asObject(before); ok {
+		if after_object, ok := asObject(after); ok {
+			operations := make([]PatchOperation, 0)
+			for _, key := range yamlKeys(before) {
+				if _, ok := after_object.get(key); !ok {
+					operations = append(operations, PatchOperation{Op: "remove", Path: pointer + "/" + escapePointerToken(key)})
+				}
+			}
+			for _, key := range yamlKeys(after) {
+				after_value, _ := after_object.get(key)
+				if before_value, ok := before_object.get(key); ok {
+					operations = append(operations, diffPatch(pointer+"/"+escapePointerToken(key), before_value, after_value)...)
+					continue
+				}
+				operations = append(operations, PatchOperation{Op: "add", Path: pointer + "/" + escapePointerToken(key), Value: cloneValue(after_value)})
+			}
+			return operations
+		}
+	}
+
+	if isKind(before, reflect.Slice) && isKind(after, reflect.Slice) {
+		before_items, after_items := toSlice(before), toSlice(after)
+		switch len(after_items) - len(before_items) {
+		case 0:
+			operations := make([]PatchOperation, 0)
+			for i := range before_items {
+				operations = append(operations, diffPatch(pointer+"/"+strconv.Itoa(i), before_items[i], after_items[i])...)
+			}
+			return operations
+		case 1:
+			i := firstDifference(before_items, after_items)
+			if equalItems(before_items[i:], after_items[i+1:]) {
+				token := strconv.Itoa(i)
+				if i == len(before_items) {
+					token = "-"
+				}
+				return []PatchOperation{{Op: "add", Path: pointer + "/" + token, Value: cloneValue(after_items[i])}}
+			}
+		case -1:
+			i := firstDifference(after_items, before_items)
+			if equalItems(before_items[i+1:], after_items[i:]) {
+				return []PatchOperation{{Op: "remove", Path: pointer + "/" + strconv.Itoa(i)}}
+			}
+		}
+	}
+	return []PatchOperation{{Op: "replace", Path: pointer, Value: cloneValue(after)}}
+}
+
+// firstDifference returns the first index where shorter and longer differ,
+// or the length of shorter.
+func firstDifference(shorter []interface{}, longer []interface{}) int {
+	i := 0
+	for i < len(shorter) && Equal(shorter[i], longer[i]) {
+		i++
+	}
+	return i
+}
+
+func equalItems(a []interface{}, b []interface{}) bool {
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPointer formats a path as a JSON Pointer (RFC 6901).
+func jsonPointer(path Path) string {
+	pointer := ""
+	for _, element := range path {
+		if element.IsIndex {
+			pointer += "/" + strconv.Itoa(element.Index)
+			continue
+		}
+		pointer += "/" + escapePointerToken(element.Key)
+	}
+	return pointer
+}
+
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+func commonPrefix(paths []Path) Path {
+	if len(paths) == 0 {
+		return Path{}
+	}
+	prefix := paths[0]
+	for _, path := range paths[1:] {
+		i := 0
+		for i < len(prefix) && i < len(path) && prefix[i] == path[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return prefix
+}
@@ -0,0 +1,387 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	doc := NewDocument(setupDocument(), WithHistory(10))
+
+	versions := []map[string]interface{}{doc.Clone()}
+	changes := []func() error{
+		func() error { return doc.UpdateProperty("one.two.three[0]", 100) },
+		func() error { return doc.CreateProperty("one.five.six", "created") },
+		func() error { return doc.DeleteProperty("one.two") },
+		func() error {
+			return doc.Update(func(tx *Tx) error {
+				if err := tx.CreateProperty("list", []interface{}{}); err != nil {
+					return err
+				}
+				return tx.UpdateProperty("list[+]", "item")
+			})
+		},
+	}
+	for i, change := range changes {
+		if err := change(); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Version() != i+1 {
+			t.Errorf("\n[%d of %d: Version] Version should be %d. Got %d", i+1, len(changes), i+1, doc.Version())
+		}
+		versions = append(versions, doc.Clone())
+	}
+
+	if err := doc.CreateProperty("one.four", 1); err == nil {
+		t.Fatal("Creating an existing property should fail")
+	}
+	if doc.Version() != len(changes) {
+		t.Error("Failed updates should not change the version")
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := doc.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(doc.Clone(), versions[i]) {
+			t.Errorf("\n[%d of %d: Undo] Results should equal \n\t%v \n \n\t%v", i+1, len(changes), doc.Clone(), versions[i])
+		}
+	}
+	if err := doc.Undo(); err == nil || err.Error() != "Nothing to undo" {
+		t.Error("Undo should fail. Got ", err)
+	}
+
+	for i := 1; i <= len(changes); i++ {
+		if err := doc.Redo(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(doc.Clone(), versions[i]) {
+			t.Errorf("\n[%d of %d: Redo] Results should equal \n\t%v \n \n\t%v", i, len(changes), doc.Clone(), versions[i])
+		}
+	}
+	if err := doc.Redo(); err == nil || err.Error() != "Nothing to redo" {
+		t.Error("Redo should fail. Got ", err)
+	}
+
+	doc.Undo()
+	doc.Undo()
+	doc.UpdateProperty("other", true)
+	if err := doc.Redo(); err == nil {
+		t.Error("A new change should discard undone changes")
+	}
+	if doc.Version() != len(changes)-1 {
+		t.Errorf("Version should be %d. Got %d", len(changes)-1, doc.Version())
+	}
+}
+
+func TestHistorySnapshot(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{"counter": 0}, WithHistory(3))
+	for i := 1; i <= 5; i++ {
+		doc.UpdateProperty("counter", i)
+	}
+	doc.Undo()
+
+	tests := []struct {
+		version  int
+		expected interface{}
+		err      bool
+	}{
+		{version: 1, err: true},
+		{version: 2, expected: 2},
+		{version: 3, expected: 3},
+		{version: 4, expected: 4},
+		{version: 5, expected: 5},
+		{version: 6, err: true},
+	}
+	for i, test := range tests {
+		snapshot, err := doc.Snapshot(test.version)
+		if test.err {
+			if err == nil {
+				t.Errorf("\n[%d of %d: Snapshot %d] Should fail", i+1, len(tests), test.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: Snapshot %d] %v", i+1, len(tests), test.version, err)
+			continue
+		}
+		if snapshot["counter"] != test.expected {
+			t.Errorf("\n[%d of %d: Snapshot %d] Counter should be %v. Got %v", i+1, len(tests), test.version, test.expected, snapshot["counter"])
+		}
+	}
+
+	if counter, _ := doc.GetProperty("counter"); counter != 4 || doc.Version() != 4 {
+		t.Error("Snapshot should not change the document. Got ", counter, doc.Version())
+	}
+	for doc.Undo() == nil {
+	}
+	if doc.Version() != 2 {
+		t.Error("History should keep the last 3 changes. Version is ", doc.Version())
+	}
+
+	plain := NewDocument(nil)
+	plain.UpdateProperty("a", 1)
+	if err := plain.Undo(); err == nil || err.Error() != "Document has no history" {
+		t.Error("Undo should fail without history. Got ", err)
+	}
+	if snapshot, err := plain.Snapshot(1); err != nil || snapshot["a"] != 1 {
+		t.Error("Current version should be available. Got ", snapshot, err)
+	}
+}
+
+func TestHistorySnapshotRandom(t *testing.T) {
+	setup := func() map[string]interface{} {
+		ordered := NewOrderedMap()
+		if err := json.Unmarshal([]byte(`{"z":1,"c":{"a":[1,{"d":2}]},"a":[{"c":3}]}`), ordered); err != nil {
+			t.Fatal(err)
+		}
+		document := setupRandomDocument()
+		document["b"] = ordered
+		return document
+	}
+	encode := func(document map[string]interface{}) string {
+		output, err := json.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(output)
+	}
+
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		doc := NewDocument(setup(), WithHistory(20))
+
+		// the document after each write, in keys order
+		versions := []string{encode(doc.Clone())}
+		changes := make([]string, 0)
+		for step := 0; step < 10; step++ {
+			change := newRandomChange(r)
+			err := change.apply(
+				func(path string, value interface{}) error { return doc.CreateProperty(path, value) },
+				func(path string, value interface{}) error { return doc.UpdateProperty(path, value) },
+				func(path string) error { return doc.DeleteProperty(path) },
+			)
+			if err == nil && doc.Version() == len(versions) {
+				versions = append(versions, encode(doc.Clone()))
+				changes = append(changes, fmt.Sprint(change.op, " ", change.path))
+			}
+			if current := encode(doc.Clone()); current != versions[len(versions)-1] {
+				t.Fatalf("\n[seed %d: Failed change %v should not change the document] \n\t%s", seed, change, current)
+			}
+		}
+
+		for version := range versions {
+			snapshot, err := doc.Snapshot(version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output := encode(snapshot); output != versions[version] {
+				t.Errorf("\n[seed %d: Snapshot %d after %v] \n\t%s \n \n\t%s", seed, version, changes, output, versions[version])
+			}
+		}
+		for version := len(versions) - 2; version >= 0; version-- {
+			doc.Undo()
+			if output := encode(doc.Clone()); output != versions[version] {
+				t.Errorf("\n[seed %d: Undo to %d after %v] \n\t%s \n \n\t%s", seed, version, changes, output, versions[version])
+			}
+		}
+	}
+}
+
+func TestHistoryPatch(t *testing.T) {
+	doc := NewDocument(setupDocument(), WithHistory(10))
+	doc.UpdateProperty("one.two.three[1]", 20)
+	doc.UpdateProperty("one/a~b", nil, ":")
+	doc.CreateProperty("one.two.three[+]", map[string]interface{}{"x": 1})
+	doc.DeleteProperty("one.four")
+	doc.UpdateProperty("ignored", true)
+	doc.Undo()
+
+	expected := `[` +
+		`{"op":"replace","path":"/one/two/three/1","value":20},` +
+		`{"op":"add","path":"/one~1a~0b","value":null},` +
+		`{"op":"add","path":"/one/two/three/-","value":{"x":1}},` +
+		`{"op":"remove","path":"/one/four"}` +
+		`]`
+	encoded, err := json.Marshal(doc.History())
+	if err != nil || string(encoded) != expected {
+		t.Errorf("Results should equal \n\t%s \n \n\t%s", encoded, expected)
+	}
+}
+
+func TestHistoryPatchApplies(t *testing.T) {
+	setup := func() map[string]interface{} {
+		return map[string]interface{}{
+			"items":   []interface{}{1, 2, 3},
+			"objects": []interface{}{map[string]interface{}{"k": 1}, map[string]interface{}{"k": 2, "j": 3}},
+			"matrix":  []interface{}{[]interface{}{1, 2}},
+			"servers": map[string]interface{}{"api.example.com": map[string]interface{}{"port": 80}},
+			"one":     map[string]interface{}{"four": 4},
+		}
+	}
+	changes := []struct {
+		name   string
+		change func(doc *Document) error
+	}{
+		{"pad an array", func(doc *Document) error { return doc.UpdateProperty("items[5]", 6) }},
+		{"create a missing array", func(doc *Document) error { return doc.UpdateProperty("list[2]", "c") }},
+		{"remove an emptied element", func(doc *Document) error { return doc.DeleteProperty("objects[0].k") }},
+		{"append", func(doc *Document) error { return doc.CreateProperty("items[+]", 7) }},
+		{"create parents", func(doc *Document) error { return doc.CreateProperty("a.b.c", 1) }},
+		{"update an element", func(doc *Document) error { return doc.UpdateProperty("objects[0].j", 4) }},
+		{"update a nested array", func(doc *Document) error { return doc.UpdateProperty("matrix[0][1]", 9) }},
+		{"update an escaped key", func(doc *Document) error { return doc.UpdateProperty(`servers.api\.example\.com.port`, 443) }},
+		{"remove an element", func(doc *Document) error { return doc.DeleteProperty("items[0]") }},
+		{"remove a key", func(doc *Document) error { return doc.DeleteProperty("one.four") }},
+		{"undo", func(doc *Document) error { return doc.Undo() }},
+		{"change in a transaction", func(doc *Document) error {
+			return doc.Update(func(tx *Tx) error {
+				if err := tx.CreateProperty("list[4].x", true); err != nil {
+					return err
+				}
+				if err := tx.UpdateProperty("items[1]", nil); err != nil {
+					return err
+				}
+				return tx.DeleteProperty("a.b")
+			})
+		}},
+	}
+
+	doc := NewDocument(setup(), WithHistory(len(changes)))
+	for i, change := range changes {
+		if err := change.change(doc); err != nil {
+			t.Fatalf("\n[%d of %d: %s] %v", i+1, len(changes), change.name, err)
+		}
+
+		var start interface{}
+		decodeJSON(t, setup(), &start)
+		var operations []map[string]interface{}
+		decodeJSON(t, doc.History(), &operations)
+		patched, err := applyPatch(start, operations)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(changes), change.name, err)
+			continue
+		}
+		var expected interface{}
+		decodeJSON(t, doc.Clone(), &expected)
+		if !reflect.DeepEqual(patched, expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(changes), change.name, patched, expected)
+		}
+	}
+}
+
+func decodeJSON(t *testing.T, value interface{}, decoded interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// applyPatch applies JSON Patch operations decoded from JSON.
+func applyPatch(document interface{}, operations []map[string]interface{}) (interface{}, error) {
+	for _, operation := range operations {
+		pointer := operation["path"].(string)
+		tokens := strings.Split(pointer, "/")[1:]
+		for i, token := range tokens {
+			tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		}
+		var err error
+		if document, err = applyOperation(document, tokens, operation["op"].(string), operation["value"]); err != nil {
+			return nil, fmt.Errorf("%s %s: %v", operation["op"], pointer, err)
+		}
+	}
+	return document, nil
+}
+
+func applyOperation(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		if op == "remove" {
+			return nil, fmt.Errorf("can not remove the document")
+		}
+		return value, nil
+	}
+	token := tokens[0]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, exists := n[token]
+		if len(tokens) > 1 || op != "add" {
+			if !exists {
+				return nil, fmt.Errorf("%s does not exist", token)
+			}
+		}
+		if len(tokens) > 1 {
+			child, err := applyOperation(child, tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[token] = child
+			return n, nil
+		}
+		if op == "remove" {
+			delete(n, token)
+		} else {
+			n[token] = value
+		}
+		return n, nil
+	case []interface{}:
+		index := len(n)
+		if token != "-" || len(tokens) > 1 || op != "add" {
+			var err error
+			if index, err = strconv.Atoi(token); err != nil {
+				return nil, err
+			}
+		}
+		limit := len(n)
+		if len(tokens) == 1 && op == "add" {
+			limit++
+		}
+		if index < 0 || index >= limit {
+			return nil, fmt.Errorf("index %d is out of range", index)
+		}
+		if len(tokens) > 1 {
+			child, err := applyOperation(n[index], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[index] = child
+			return n, nil
+		}
+		switch op {
+		case "add":
+			n = append(n[:index], append([]interface{}{value}, n[index:]...)...)
+		case "replace":
+			n[index] = value
+		case "remove":
+			n = append(n[:index], n[index+1:]...)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s: is not an object or an array", token)
+}
+
+func TestHistoryWatch(t *testing.T) {
+	doc := NewDocument(setupFeatures(), WithHistory(10))
+	doc.UpdateProperty("features.search.enabled", true)
+
+	events, cancel := doc.Watch("features.*.enabled")
+	defer cancel()
+	doc.Undo()
+	doc.Redo()
+
+	expected := []Event{
+		{Path: "features.search.enabled", Op: EventUpdate, Old: true, New: false},
+		{Path: "features.search.enabled", Op: EventUpdate, Old: false, New: true},
+	}
+	if received := receive(events); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Events should equal \n\t%v \n \n\t%v", received, expected)
+	}
+}
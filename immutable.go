@@ -0,0 +1,143 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// With returns a new document where the property at path holds value.
+// The passed document is never changed: only the maps and arrays along
+// the path are copied, every other subtree is shared with the original.
+// The path means the same location it means to UpdateProperty, which
+// sets the property on the copy: missing maps are created and arrays are
+// padded with nil. `[+]` and `[-]` in place of an index append to the array.
+//
+//	updated, err := With(cached, "user.name", "John")
+//	// cached["user"] is unchanged, updated["user"] is a new map
+//	updated, err := With(cached, "user.tags[+]", "new")
+func With(original_data map[string]interface{}, path string, value interface{}, separator_arr ...string) (map[string]interface{}, error) {
+	separator := getSeparator(separator_arr)
+	if level, _ := nextLevel(path, separator, 0); len(level) == 0 {
+		return nil, fmt.Errorf("Path is empty")
+	}
+
+	root := copyPath(original_data, path, separator)
+	if err := updateProperty(mapObject(root), path, value, separator); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Without returns a new document without the property at path.
+// The passed document is never changed, see With. The path means the same
+// location it means to DeleteProperty, which removes the property from the
+// copy. Paths can not use the `[+]` and `[-]` append tokens: there is no
+// element to remove.
+//
+//	updated, err := Without(cached, "user.tokens[0]")
+func Without(original_data map[string]interface{}, path string, separator_arr ...string) (map[string]interface{}, error) {
+	separator := getSeparator(separator_arr)
+	if level, _ := nextLevel(path, separator, 0); len(level) == 0 {
+		return nil, fmt.Errorf("Path is empty")
+	}
+	if parsed, err := parsePath(path, separator, true); err == nil {
+		for _, element := range parsed {
+			if element.IsIndex && element.Index == appendIndex {
+				return nil, fmt.Errorf("%s: [+] and [-] append to an array and can not be removed", path)
+			}
+		}
+	}
+
+	root := copyPath(original_data, path, separator)
+	if err := deleteProperty(mapObject(root), path, separator); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// copyPath returns a copy of original_data where the objects along path,
+// and the arrays holding them, are copied. CRUD functions only write these
+// objects and always reallocate arrays, so changing the copy with them
+// leaves original_data unchanged.
+func copyPath(original_data map[string]interface{}, path string, separator string) map[string]interface{} {
+	var parsed Path
+	if usesPathGrammar(path, separator) {
+		parsed, _ = parsePath(path, separator, true)
+	} else {
+		for _, level := range splitLevels(resolveAppendTokens(mapObject(original_data), path, separator), separator) {
+			property, index_found, ok := parseIndexLevel(level)
+			if !ok {
+				parsed = append(parsed, PathElement{Key: level})
+				continue
+			}
+			index, _ := strconv.Atoi(index_found)
+			parsed = append(parsed, PathElement{Key: property}, PathElement{Index: index, IsIndex: true})
+		}
+	}
+
+	root, _ := copyAlong(original_data, parsed, 0)
+	return root.(map[string]interface{})
+}
+
+// copyAlong returns node with the objects reached by path[level:] copied.
+// Arrays are copied only when one of their elements is. It reports whether
+// node was copied.
+func copyAlong(node interface{}, path Path, level int) (interface{}, bool) {
+	mapped_value, ok := asObject(node)
+	if level < len(path) && path[level].IsIndex {
+		if ok {
+			// `property[index]` on an object writes the object itself
+			return copyAlong(node, path, level+1)
+		}
+		if !isKind(node, reflect.Slice) {
+			return node, false
+		}
+		items := reflect.ValueOf(node)
+		index := path[level].Index
+		if index < 0 || index >= items.Len() {
+			return node, false
+		}
+		child, copied := copyAlong(items.Index(index).Interface(), path, level+1)
+		if !copied {
+			return node, false
+		}
+		copied_items := reflect.MakeSlice(items.Type(), items.Len(), items.Len())
+		reflect.Copy(copied_items, items)
+		copied_items.Index(index).Set(reflect.ValueOf(child))
+		return copied_items.Interface(), true
+	}
+	if !ok {
+		return node, false
+	}
+
+	copied_object, copied := copyObject(mapped_value)
+	if level < len(path) {
+		if child, ok := mapped_value.get(path[level].Key); ok {
+			if child, changed := copyAlong(child, path, level+1); changed {
+				copied_object.set(path[level].Key, child)
+			}
+		}
+	}
+	return copied, true
+}
+
+// copyObject returns a shallow copy of an object of the same type and
+// the value to store.
+func copyObject(data object) (object, interface{}) {
+	switch d := data.(type) {
+	case *OrderedMap:
+		copied := NewOrderedMap()
+		for _, key := range d.Keys() {
+			copied.Set(key, d.values[key])
+		}
+		return copied, copied
+	case mapObject:
+		copied := make(map[string]interface{}, len(d)+1)
+		for key, value := range d {
+			copied[key] = value
+		}
+		return mapObject(copied), copied
+	}
+	return data, data
+}
@@ -0,0 +1,219 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		path     string
+		value    interface{}
+		expected map[string]interface{}
+		err      error
+	}{
+		{
+			path:  "one.two.three[1]",
+			value: 20,
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two":  map[string]interface{}{"three": []interface{}{1, 20, 3}},
+					"four": map[string]interface{}{"five": []int{11, 22, 33}},
+				},
+			},
+		},
+		{
+			path:  "one.six.seven[2]",
+			value: true,
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two":  map[string]interface{}{"three": []int{1, 2, 3}},
+					"four": map[string]interface{}{"five": []int{11, 22, 33}},
+					"six":  map[string]interface{}{"seven": []interface{}{nil, nil, true}},
+				},
+			},
+		},
+		{
+			path:  "one.two.three[+]",
+			value: 4,
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two":  map[string]interface{}{"three": []interface{}{1, 2, 3, 4}},
+					"four": map[string]interface{}{"five": []int{11, 22, 33}},
+				},
+			},
+		},
+		{
+			path:  "one.six[-].seven",
+			value: true,
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two":  map[string]interface{}{"three": []int{1, 2, 3}},
+					"four": map[string]interface{}{"five": []int{11, 22, 33}},
+					"six":  []interface{}{map[string]interface{}{"seven": true}},
+				},
+			},
+		},
+		{
+			path: "",
+			err:  fmt.Errorf("Path is empty"),
+		},
+	}
+
+	for i, test := range tests {
+		original := setupDocument()
+		result, err := With(original, test.path, test.value)
+		if !reflect.DeepEqual(original, setupDocument()) {
+			t.Errorf("\n[%d of %d: %s] Original document should not change", i+1, len(tests), test.path)
+		}
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, result, test.expected)
+		}
+	}
+}
+
+func TestWithout(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected map[string]interface{}
+		err      error
+	}{
+		{
+			path: "one.two.three[0]",
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two":  map[string]interface{}{"three": []interface{}{2, 3}},
+					"four": map[string]interface{}{"five": []int{11, 22, 33}},
+				},
+			},
+		},
+		{
+			path: "one.four",
+			expected: map[string]interface{}{
+				"one": map[string]interface{}{
+					"two": map[string]interface{}{"three": []int{1, 2, 3}},
+				},
+			},
+		},
+		{
+			path: "one.six",
+			err:  fmt.Errorf("Property six does not exist"),
+		},
+		{
+			path: "one.two.three[3]",
+			err:  fmt.Errorf("three: Min index is 0, Max index is 3. You passed index 3"),
+		},
+		{
+			path: "one.two.three[+]",
+			err:  fmt.Errorf("one.two.three[+]: [+] and [-] append to an array and can not be removed"),
+		},
+	}
+
+	for i, test := range tests {
+		original := setupDocument()
+		result, err := Without(original, test.path)
+		if !reflect.DeepEqual(original, setupDocument()) {
+			t.Errorf("\n[%d of %d: %s] Original document should not change", i+1, len(tests), test.path)
+		}
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] %v", i+1, len(tests), test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(tests), test.path, result, test.expected)
+		}
+	}
+}
+
+func TestWithSharesUnchangedSubtrees(t *testing.T) {
+	original := setupDocument()
+	result, err := With(original, "one.two.name", "changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same := func(a, b interface{}) bool {
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+	one := original["one"].(map[string]interface{})
+	new_one := result["one"].(map[string]interface{})
+	if same(one, new_one) || same(one["two"], new_one["two"]) {
+		t.Error("Maps along the path should be copied")
+	}
+	if !same(one["four"], new_one["four"]) {
+		t.Error("Unchanged subtrees should be shared")
+	}
+	if !same(one["two"].(map[string]interface{})["three"], new_one["two"].(map[string]interface{})["three"]) {
+		t.Error("Unchanged arrays should be shared")
+	}
+}
+
+func TestWithMatchesCRUD(t *testing.T) {
+	setup := func() map[string]interface{} {
+		ordered := NewOrderedMap()
+		ordered.Set("a", 1)
+		ordered.Set("b", map[string]interface{}{"c": 2})
+		return map[string]interface{}{
+			"one":     map[string]interface{}{"two": map[string]interface{}{"three": []int{1, 2, 3}}},
+			"list":    []interface{}{map[string]interface{}{"k": 1}, "s"},
+			"matrix":  []interface{}{[]interface{}{1, 2}},
+			"ordered": ordered,
+			"scalar":  "x",
+			"nil":     nil,
+			"typed":   map[string]int{"a": 1},
+			"servers": map[string]interface{}{"api.example.com": map[string]interface{}{"port": 80}},
+		}
+	}
+	paths := []string{
+		"a", "a.b.c", "one.two", "one..two", "one.two.three[1]", "one.two.three[5]", "one.two.three[+]",
+		"list[0]", "list[0].k", "list[0].new", "list[1].k", "list[4]", "list[3].x", "list[-]",
+		"missing[2]", "missing[1].x", "matrix[0][1]", "matrix[0][3]", "matrix[1][0]",
+		"ordered.a", "ordered.b.c", "ordered.d", "scalar.x", "scalar[0]", "nil.x", "nil[0]",
+		"typed.b", "typed.a.b", `servers.api\.example\.com.port`, `servers.api\.example\.com`,
+	}
+
+	for i, path := range paths {
+		updated := setup()
+		update_err := UpdateProperty(updated, path, 7)
+		original := setup()
+		result, err := With(original, path, 7)
+		if !Equal(original, setup()) {
+			t.Errorf("\n[%d of %d: %s] Original document should not change", i+1, len(paths), path)
+		}
+		if fmt.Sprint(err) != fmt.Sprint(update_err) || (err == nil && !Equal(result, updated)) {
+			t.Errorf("\n[%d of %d: %s] With should equal UpdateProperty \n\t%v %v \n \n\t%v %v", i+1, len(paths), path, result, err, updated, update_err)
+		}
+
+		deleted := setup()
+		delete_err := DeleteProperty(deleted, path)
+		original = setup()
+		result, err = Without(original, path)
+		if !Equal(original, setup()) {
+			t.Errorf("\n[%d of %d: %s] Original document should not change", i+1, len(paths), path)
+		}
+		if strings.Contains(path, "[+]") || strings.Contains(path, "[-]") {
+			continue
+		}
+		if fmt.Sprint(err) != fmt.Sprint(delete_err) || (err == nil && !Equal(result, deleted)) {
+			t.Errorf("\n[%d of %d: %s] Without should equal DeleteProperty \n\t%v %v \n \n\t%v %v", i+1, len(paths), path, result, err, deleted, delete_err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Interpolate replaces `${path}` placeholders in string values of a document
+// with values of the referenced properties:
+//
+//	${database.host}       value of a property
+//	${database.port:-5432} value of a property or a default if it does not exist
+//	${env:HOME}            value of an environment variable
+//	${env:HOME:-/root}     value of an environment variable or a default
+//	$${literal}            `${literal}` without interpolation
+//
+// A string consisting of a single placeholder is replaced with the referenced
+// value keeping its type, otherwise values are formatted into the string.
+// Referenced properties are interpolated first, reference cycles are reported
+// with the chain of properties.
+//
+//	document := map[string]interface{}{
+//		"database": map[string]interface{}{"host": "localhost", "port": 5432},
+//		"url":      "http://${database.host}:${database.port}/db",
+//	}
+//	err := Interpolate(document) // url: http://localhost:5432/db
+func Interpolate(original_data map[string]interface{}) error {
+	in := &interpolator{
+		original_data: original_data,
+		done:          make(map[string]bool),
+	}
+	return in.children(Path{}, original_data)
+}
+
+type interpolator struct {
+	original_data map[string]interface{}
+	done          map[string]bool
+	stack         []string
+}
+
+// node interpolates a property and everything below it.
+func (in *interpolator) node(path Path) error {
+	key := path.String()
+	if in.done[key] {
+		return nil
+	}
+	for i, visiting := range in.stack {
+		if visiting == key {
+			chain := append(append([]string{}, in.stack[i:]...), key)
+			return fmt.Errorf("Reference cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	value, ok := getPath(in.original_data, path)
+	if !ok {
+		return nil
+	}
+
+	in.stack = append(in.stack, key)
+	defer func() {
+		in.stack = in.stack[:len(in.stack)-1]
+	}()
+
+	if s, ok := value.(string); ok {
+		if strings.Contains(s, "${") {
+			expanded, err := in.expand(key, s)
+			if err != nil {
+				return err
+			}
+			if err = assignPath(in.original_data, path, expanded); err != nil {
+				return err
+			}
+		}
+	} else if err := in.children(path, value); err != nil {
+		return err
+	}
+
+	in.done[key] = true
+	return nil
+}
+
+func (in *interpolator) children(path Path, value interface{}) error {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		if !isStringMap(v) {
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := in.node(path.Child(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := in.node(path.Item(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expand replaces placeholders in a string.
+func (in *interpolator) expand(key string, s string) (interface{}, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			break
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%s: unterminated placeholder in %q", key, s)
+		}
+		end += start
+
+		value, err := in.resolve(key, s[start+2:end])
+		if err != nil {
+			return nil, err
+		}
+		if start == 0 && end == len(s)-1 && b.Len() == 0 {
+			return value, nil
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString(interpolationString(value))
+		s = s[end+1:]
+	}
+	return b.String(), nil
+}
+
+// resolve returns the value of a placeholder expression.
+func (in *interpolator) resolve(key string, expression string) (interface{}, error) {
+	reference, fallback, has_fallback := expression, "", false
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		reference, fallback, has_fallback = expression[:i], expression[i+2:], true
+	}
+	reference = strings.TrimSpace(reference)
+
+	if strings.HasPrefix(reference, "env:") {
+		name := strings.TrimPrefix(reference, "env:")
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		if has_fallback {
+			return fallback, nil
+		}
+		return nil, fmt.Errorf("%s: environment variable %s is not set", key, name)
+	}
+
+	path, err := ParsePath(reference)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", key, err)
+	}
+	if err = in.node(path); err != nil {
+		return nil, err
+	}
+
+	value, err := getPathProperty(in.original_data, path, ".")
+	if err != nil {
+		if has_fallback {
+			return fallback, nil
+		}
+		return nil, fmt.Errorf("%s: %v", key, err)
+	}
+	return value, nil
+}
+
+// interpolationString formats a value inserted into a string.
+func interpolationString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprint(value)
+}
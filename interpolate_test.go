@@ -0,0 +1,130 @@
+package gjm
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("GJM_TEST_HOME", "/home/gjm")
+	defer os.Unsetenv("GJM_TEST_HOME")
+
+	document := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host": "${hosts[0]}",
+			"port": 5432,
+			"tags": []string{"${database.host}"},
+		},
+		"hosts":         []interface{}{"localhost", "remote"},
+		"url":           "http://${database.host}:${database.port}/db",
+		"port":          "${database.port}",
+		"database_copy": "${database}",
+		"timeout":       "${missing.timeout:-30}",
+		"home":          "${env:GJM_TEST_HOME}/app",
+		"shell":         "${env:GJM_TEST_MISSING:-/bin/sh}",
+		"literal":       "$${database.host}",
+		"list":          "${hosts}",
+		"matrix":        []interface{}{[]interface{}{1, 2}},
+		"cell":          "${matrix[0][1]}",
+		"api.host":      "example.com",
+		"api_url":       "https://${api\\.host}/v1",
+	}
+
+	if err := Interpolate(document); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"port": 5432,
+			"tags": []string{"localhost"},
+		},
+		"hosts": []interface{}{"localhost", "remote"},
+		"url":   "http://localhost:5432/db",
+		"port":  5432,
+		"database_copy": map[string]interface{}{
+			"host": "localhost",
+			"port": 5432,
+			"tags": []string{"localhost"},
+		},
+		"timeout":  "30",
+		"home":     "/home/gjm/app",
+		"shell":    "/bin/sh",
+		"literal":  "${database.host}",
+		"list":     []interface{}{"localhost", "remote"},
+		"matrix":   []interface{}{[]interface{}{1, 2}},
+		"cell":     2,
+		"api.host": "example.com",
+		"api_url":  "https://example.com/v1",
+	}
+	if !reflect.DeepEqual(document, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", document, expected)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	cases := []struct {
+		in  map[string]interface{}
+		err error
+	}{
+		{
+			in: map[string]interface{}{
+				"a": "${b}",
+				"b": map[string]interface{}{"c": "${d}"},
+				"d": "x${a}",
+			},
+			err: fmt.Errorf("Reference cycle: a -> b -> b.c -> d -> a"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": "${a}",
+			},
+			err: fmt.Errorf("Reference cycle: a -> a"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": "${missing}",
+			},
+			err: fmt.Errorf("a: Property missing does not exist"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": "${b[0][2]}",
+				"b": []interface{}{[]interface{}{1}},
+			},
+			err: fmt.Errorf("a: b[0]: Min index is 0, Max index is 1. You passed index 2"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": "${env:GJM_TEST_MISSING}",
+			},
+			err: fmt.Errorf("a: environment variable GJM_TEST_MISSING is not set"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": "${b",
+			},
+			err: fmt.Errorf("a: unterminated placeholder in \"${b\""),
+		},
+		{
+			in: map[string]interface{}{
+				"a": []string{"${b}"},
+				"b": 1,
+			},
+			err: fmt.Errorf("a[0]: can not assign int to string"),
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		err := Interpolate(c.in)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package gjm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParseJSON decodes a JSON object keeping numbers as json.Number,
+// so integers above 2^53 are not rounded by a float64 conversion.
+// Read numbers with GetInt64, GetUint64 or GetFloat64.
+//
+//	document, err := ParseJSON(body)
+//	id, err := GetInt64(document, "user.id")
+func ParseJSON(data []byte) (map[string]interface{}, error) {
+	reader := bytes.NewReader(data)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	document := make(map[string]interface{})
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	// The decoder reads ahead: the document ends where its buffer starts
+	buffered, _ := ioutil.ReadAll(decoder.Buffered())
+	offset := len(data) - reader.Len() - len(buffered)
+	trailing := bytes.TrimLeft(data[offset:], " \t\r\n")
+	if len(trailing) > 0 {
+		return nil, fmt.Errorf("Invalid JSON: unexpected data at offset %d", len(data)-len(trailing))
+	}
+	return document, nil
+}
+
+// MarshalOption configures MarshalJSON.
+type MarshalOption func(*marshalOptions)
+
+type marshalOptions struct {
+	sort_keys   bool
+	prefix      string
+	indent      string
+	escape_html bool
+	canonical   bool
+}
+
+// SortKeys sorts the keys of OrderedMap values, which are written in
+// their order otherwise. Keys of maps are always sorted.
+func SortKeys() MarshalOption {
+	return func(o *marshalOptions) {
+		o.sort_keys = true
+	}
+}
+
+// Indent writes every element on a new line starting with prefix,
+// indented with one copy of indent per level, like json.MarshalIndent.
+func Indent(prefix string, indent string) MarshalOption {
+	return func(o *marshalOptions) {
+		o.prefix = prefix
+		o.indent = indent
+	}
+}
+
+// EscapeHTML escapes <, > and & in strings like json.Marshal does.
+func EscapeHTML() MarshalOption {
+	return func(o *marshalOptions) {
+		o.escape_html = true
+	}
+}
+
+// Canonical writes the RFC 8785 JSON Canonicalization Scheme form of the document:
+// no whitespace, keys sorted by their UTF-16 code units, numbers written as
+// IEEE 754 doubles the way ECMAScript does and minimal string escaping.
+// Two equal documents always give the same bytes, so the output can be signed.
+// Other options are ignored. Numbers which are not exact doubles, like
+// integers above 2^53, are rounded as RFC 8785 requires.
+func Canonical() MarshalOption {
+	return func(o *marshalOptions) {
+		o.canonical = true
+	}
+}
+
+// MarshalJSON encodes a document. json.Number values are written as they
+// are, OrderedMap keys in their order. HTML characters are not escaped
+// unless EscapeHTML is passed.
+//
+//	output, err := MarshalJSON(document)
+//	output, err := MarshalJSON(document, SortKeys(), Indent("", "  "))
+//	signed, err := MarshalJSON(document, Canonical())
+func MarshalJSON(document interface{}, opts ...MarshalOption) ([]byte, error) {
+	options := &marshalOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var buffer bytes.Buffer
+	if err := options.encode(&buffer, document); err != nil {
+		return nil, err
+	}
+	if options.canonical || (len(options.prefix) == 0 && len(options.indent) == 0) {
+		return buffer.Bytes(), nil
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buffer.Bytes(), options.prefix, options.indent); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+func (o *marshalOptions) encode(buffer *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case string:
+		o.encodeString(buffer, v)
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		if !o.canonical {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buffer.Write(encoded)
+			return nil
+		}
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return fmt.Errorf("%s can not be represented as a double", v)
+		}
+		return encodeCanonicalNumber(buffer, f)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		o.sortKeys(keys)
+		return o.encodeObject(buffer, keys, func(key string) interface{} { return v[key] })
+	case *OrderedMap:
+		if v == nil {
+			buffer.WriteString("null")
+			return nil
+		}
+		keys := v.Keys()
+		if o.sort_keys || o.canonical {
+			o.sortKeys(keys)
+		}
+		return o.encodeObject(buffer, keys, func(key string) interface{} { return v.values[key] })
+	case []interface{}:
+		buffer.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := o.encode(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	default:
+		return o.encodeOther(buffer, value)
+	}
+	return nil
+}
+
+// encodeOther encodes values of other types with encoding/json. In canonical
+// mode or with sorted keys the result is decoded and encoded again.
+func (o *marshalOptions) encodeOther(buffer *bytes.Buffer, value interface{}) error {
+	if _, marshaler := value.(json.Marshaler); o.canonical && !marshaler {
+		if f, ok := floatValue(value); ok {
+			return encodeCanonicalNumber(buffer, f)
+		}
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(o.escape_html)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	if !o.canonical && !o.sort_keys {
+		buffer.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+		return nil
+	}
+
+	decoder := json.NewDecoder(&encoded)
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	return o.encode(buffer, decoded)
+}
+
+func (o *marshalOptions) encodeObject(buffer *bytes.Buffer, keys []string, get func(key string) interface{}) error {
+	buffer.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		o.encodeString(buffer, key)
+		buffer.WriteByte(':')
+		if err := o.encode(buffer, get(key)); err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte('}')
+	return nil
+}
+
+// sortKeys sorts keys by bytes, or by UTF-16 code units in canonical mode.
+func (o *marshalOptions) sortKeys(keys []string) {
+	if !o.canonical {
+		sort.Strings(keys)
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := utf16.Encode([]rune(keys[i])), utf16.Encode([]rune(keys[j]))
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+}
+
+func (o *marshalOptions) encodeString(buffer *bytes.Buffer, s string) {
+	if !o.canonical {
+		var encoded bytes.Buffer
+		encoder := json.NewEncoder(&encoded)
+		encoder.SetEscapeHTML(o.escape_html)
+		encoder.Encode(s)
+		buffer.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+		return
+	}
+
+	// RFC 8785 escapes quotes, backslashes and control characters only
+	buffer.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, r)
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+}
+
+// floatValue returns numbers of Go numeric types as float64.
+func floatValue(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// encodeCanonicalNumber writes a double the way ECMAScript Number.prototype.toString does.
+func encodeCanonicalNumber(buffer *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%v is not a valid JSON number", f)
+	}
+	buffer.WriteString(formatDouble(f))
+	return nil
+}
+
+// formatDouble formats a finite double the way ECMAScript Number.prototype.toString does.
+func formatDouble(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// 1e-07 is written 1e-7
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	document, err := ParseJSON([]byte(`{"id": 9007199254740993, "price": 1.10, "items": [{"n": 1}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := GetProperty(document, "id"); id != json.Number("9007199254740993") {
+		t.Errorf("Numbers should be kept as json.Number, got %#v", id)
+	}
+	if n, _ := GetProperty(document, "items[0].n"); n != json.Number("1") {
+		t.Errorf("Nested numbers should be kept as json.Number, got %#v", n)
+	}
+
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{input: `{"a": 1} {"b": 2}`, err: fmt.Errorf("Invalid JSON: unexpected data at offset 9")},
+		{input: `[1]`, err: fmt.Errorf("json: cannot unmarshal array into Go value of type map[string]interface {}")},
+		{input: `{"a": `, err: fmt.Errorf("unexpected EOF")},
+	}
+	for i, test := range tests {
+		_, err := ParseJSON([]byte(test.input))
+		if fmt.Sprint(err) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.input, err, test.err)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	ordered := NewOrderedMap()
+	ordered.Set("b", 1)
+	ordered.Set("a", json.Number("9007199254740993"))
+
+	document := map[string]interface{}{
+		"z":       "<tag> & more",
+		"ordered": ordered,
+		"typed":   map[string]int{"y": 2, "x": 1},
+		"list":    []interface{}{true, nil, 1.5},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []MarshalOption
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `{"list":[true,null,1.5],"ordered":{"b":1,"a":9007199254740993},"typed":{"x":1,"y":2},"z":"<tag> & more"}`,
+		},
+		{
+			name:     "sorted keys",
+			opts:     []MarshalOption{SortKeys()},
+			expected: `{"list":[true,null,1.5],"ordered":{"a":9007199254740993,"b":1},"typed":{"x":1,"y":2},"z":"<tag> & more"}`,
+		},
+		{
+			name:     "escaped HTML",
+			opts:     []MarshalOption{EscapeHTML()},
+			expected: `{"list":[true,null,1.5],"ordered":{"b":1,"a":9007199254740993},"typed":{"x":1,"y":2},"z":"\u003ctag\u003e \u0026 more"}`,
+		},
+		{
+			name:     "indented",
+			opts:     []MarshalOption{Indent("", "  ")},
+			expected: "{\n  \"list\": [\n    true,\n    null,\n    1.5\n  ],\n  \"ordered\": {\n    \"b\": 1,\n    \"a\": 9007199254740993\n  },\n  \"typed\": {\n    \"x\": 1,\n    \"y\": 2\n  },\n  \"z\": \"<tag> & more\"\n}",
+		},
+		{
+			name:     "canonical",
+			opts:     []MarshalOption{Canonical(), Indent("", "  "), EscapeHTML()},
+			expected: `{"list":[true,null,1.5],"ordered":{"a":9007199254740992,"b":1},"typed":{"x":1,"y":2},"z":"<tag> & more"}`,
+		},
+	}
+
+	for i, test := range tests {
+		output, err := MarshalJSON(document, test.opts...)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+		}
+		if string(output) != test.expected {
+			t.Errorf("\n[%d of %d: %s] Output should equal \n\t%s \n \n\t%s", i+1, len(tests), test.name, output, test.expected)
+		}
+	}
+}
+
+func TestMarshalJSONCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			// RFC 8785 section 3.2.2
+			name:     "rfc example",
+			input:    `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3
+			name:     "utf-16 key order",
+			input:    `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:     "numbers",
+			input:    `{"a": -0, "b": 1e21, "c": 1e-7, "d": 100, "e": 0.000001, "f": -1.5e-10, "g": 123456789012345678901}`,
+			expected: `{"a":0,"b":1e+21,"c":1e-7,"d":100,"e":0.000001,"f":-1.5e-10,"g":123456789012345680000}`,
+		},
+	}
+
+	for i, test := range tests {
+		document, err := ParseJSON([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, err := MarshalJSON(document, Canonical())
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+		}
+		if string(output) != test.expected {
+			t.Errorf("\n[%d of %d: %s] Output should equal \n\t%s \n \n\t%s", i+1, len(tests), test.name, output, test.expected)
+		}
+	}
+
+	if _, err := MarshalJSON(map[string]interface{}{"a": json.Number("1e400")}, Canonical()); err == nil {
+		t.Error("Numbers out of the double range should fail")
+	}
+}
@@ -0,0 +1,427 @@
+package gjm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Result is the value of a path read by GetMany.
+type Result struct {
+	Path  string
+	Value interface{}
+	Err   error
+}
+
+// RawResult is the raw JSON of a path read by GetManyBytes.
+type RawResult struct {
+	Path string
+	Raw  []byte
+	Err  error
+}
+
+// GetMany returns several properties walking the document once: paths
+// sharing a prefix are resolved together. Results are in the order of paths,
+// each holds the value and the error GetProperty would return for its path.
+//
+// Paths use the default separator, see GetManySep.
+//
+//	results := GetMany(event, "user.id", "user.name", "items[0].sku")
+//	for _, result := range results {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func GetMany(original_data map[string]interface{}, paths ...string) []Result {
+	return GetManySep(original_data, paths, getSeparator(nil))
+}
+
+// GetManySep is GetMany with a separator.
+//
+//	results := GetManySep(event, []string{"user/id", "items[0]/sku"}, "/")
+func GetManySep(original_data map[string]interface{}, paths []string, separator string) []Result {
+	separator = getSeparator([]string{separator})
+	results := make([]Result, len(paths))
+	for i, path := range paths {
+		results[i].Path = path
+	}
+
+	trie := newPathTrie(paths, separator)
+	for _, i := range trie.document {
+		results[i].Value, results[i].Err = GetProperty(original_data, paths[i], separator)
+	}
+	for _, i := range trie.invalid {
+		results[i].Err = fmt.Errorf("Property %s does not exist", paths[i])
+	}
+
+	trie.visit(trie.root, original_data, func(i int, value interface{}, err error) {
+		results[i].Value, results[i].Err = value, err
+	})
+	return results
+}
+
+// GetManyBytes returns the raw JSON of several properties scanning the
+// document once. Each result holds what GetBytes would return for its path.
+//
+// Paths use the default separator, see GetManyBytesSep.
+//
+//	results := GetManyBytes(payload, "action", "pull_request.user.login")
+func GetManyBytes(data []byte, paths ...string) []RawResult {
+	return GetManyBytesSep(data, paths, getSeparator(nil))
+}
+
+// GetManyBytesSep is GetManyBytes with a separator.
+//
+//	results := GetManyBytesSep(payload, []string{"action", "pull_request/user/login"}, "/")
+func GetManyBytesSep(data []byte, paths []string, separator string) []RawResult {
+	separator = getSeparator([]string{separator})
+	results := make([]RawResult, len(paths))
+	for i, path := range paths {
+		results[i].Path = path
+	}
+
+	trie := newPathTrie(paths, separator)
+	for _, i := range trie.document {
+		results[i].Raw, results[i].Err = GetBytes(data, paths[i], separator)
+	}
+	for _, i := range trie.invalid {
+		results[i].Err = fmt.Errorf("Property %s does not exist", paths[i])
+	}
+
+	resolve := func(i int, span [2]int, err error) {
+		if err == nil {
+			results[i].Raw = data[span[0]:span[1]]
+		}
+		results[i].Err = err
+	}
+	s := &scanner{data: data}
+	if len(trie.root.all) > 0 && s.peek() != '{' {
+		for _, i := range trie.root.all {
+			resolve(i, [2]int{}, fmt.Errorf("Property %s does not exist", paths[i]))
+		}
+		return results
+	}
+	trie.visitBytes(trie.root, s, resolve)
+	return results
+}
+
+// pathTrie is a prefix tree of paths split into levels.
+type pathTrie struct {
+	separator string
+	levels    [][]string
+	paths     []string
+	root      *trieNode
+	// paths of the whole document and paths without levels
+	document []int
+	invalid  []int
+}
+
+type trieNode struct {
+	level       string
+	property    string
+	index_found string
+	indexed     bool
+	depth       int
+	children    []*trieNode
+	// paths ending at the node and every path going through it
+	ends []int
+	all  []int
+}
+
+func newPathTrie(paths []string, separator string) *pathTrie {
+	trie := &pathTrie{
+		separator: separator,
+		levels:    make([][]string, len(paths)),
+		paths:     paths,
+		root:      &trieNode{depth: -1},
+	}
+
+	for i, path := range paths {
+		if len(path) == 0 || path == separator {
+			trie.document = append(trie.document, i)
+			continue
+		}
+		levels := make([]string, 0)
+		for level, next := nextLevel(path, separator, 0); len(level) > 0; level, next = nextLevel(path, separator, next) {
+			levels = append(levels, level)
+		}
+		if len(levels) == 0 {
+			trie.invalid = append(trie.invalid, i)
+			continue
+		}
+		trie.levels[i] = levels
+
+		node := trie.root
+		node.all = append(node.all, i)
+		for _, level := range levels {
+			var child *trieNode
+			for _, c := range node.children {
+				if c.level == level {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &trieNode{level: level, property: level, depth: node.depth + 1}
+				if property, index_found, indexed := parseIndexLevel(level); indexed {
+					child.property, child.index_found, child.indexed = property, index_found, true
+				}
+				node.children = append(node.children, child)
+			}
+			child.all = append(child.all, i)
+			node = child
+		}
+		node.ends = append(node.ends, i)
+	}
+	return trie
+}
+
+// remaining returns the error message path of a path at a depth,
+// the way GetProperty reports it.
+func (t *pathTrie) remaining(i int, depth int) string {
+	if depth == 0 {
+		return t.paths[i]
+	}
+	return strings.Join(t.levels[i][depth:], t.separator)
+}
+
+// fail sets an error for every path going through node but not ending at it.
+func (t *pathTrie) fail(node *trieNode, resolve func(i int, err error), err func(i int) error) {
+	ends := make(map[int]bool, len(node.ends))
+	for _, i := range node.ends {
+		ends[i] = true
+	}
+	for _, i := range node.all {
+		if !ends[i] {
+			resolve(i, err(i))
+		}
+	}
+}
+
+func (t *pathTrie) visit(node *trieNode, data interface{}, resolve func(i int, value interface{}, err error)) {
+	failPath := func(i int, err error) {
+		resolve(i, nil, err)
+	}
+
+	for _, child := range node.children {
+		value, ok := lookupKey(data, child.property)
+
+		if child.indexed {
+			index, err := strconv.Atoi(child.index_found)
+			if err != nil {
+				err = fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", child.property, child.index_found),
+					"number",
+				)
+			} else if !ok {
+				err = fmt.Errorf(
+					"Property %s does not exist", child.property,
+				)
+			} else {
+				value, err = indexSlice(value, child.property, index)
+			}
+			if err != nil {
+				for _, i := range child.all {
+					failPath(i, err)
+				}
+				continue
+			}
+		}
+
+		for _, i := range child.ends {
+			if ok {
+				resolve(i, value, nil)
+			} else {
+				failPath(i, fmt.Errorf("Property %s does not exist", t.remaining(i, child.depth)))
+			}
+		}
+		if len(child.children) == 0 {
+			continue
+		}
+
+		switch {
+		case !ok:
+			t.fail(child, failPath, func(i int) error {
+				return fmt.Errorf("Property %s does not exist", child.property)
+			})
+		case !isObject(value):
+			t.fail(child, failPath, func(i int) error {
+				return fmt.Errorf("Property %s does not exist", t.remaining(i, child.depth))
+			})
+		default:
+			t.visit(child, value, resolve)
+		}
+	}
+}
+
+// visitBytes resolves the children of node in the object starting at the
+// position of the scanner. Every key of the object is scanned once.
+func (t *pathTrie) visitBytes(node *trieNode, s *scanner, resolve func(i int, span [2]int, err error)) {
+	failPath := func(i int, err error) {
+		resolve(i, [2]int{}, err)
+	}
+
+	spans := make(map[string][2]int, len(node.children))
+	wanted := make(map[string]bool, len(node.children))
+	for _, child := range node.children {
+		wanted[child.property] = true
+	}
+	if err := scanObject(s, func(key string) (bool, error) {
+		if !wanted[key] {
+			return false, nil
+		}
+		// Like GetBytes the last of duplicate keys wins
+		start := s.pos
+		if err := s.skipValue(); err != nil {
+			return false, err
+		}
+		spans[key] = [2]int{start, s.pos}
+		return true, nil
+	}); err != nil {
+		for _, i := range node.all {
+			failPath(i, err)
+		}
+		return
+	}
+
+	elements := make(map[string][][2]int)
+	for _, child := range node.children {
+		span, ok := spans[child.property]
+
+		if child.indexed {
+			index, err := strconv.Atoi(child.index_found)
+			switch {
+			case err != nil:
+				err = fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", child.property, child.index_found),
+					"number",
+				)
+			case !ok:
+				err = fmt.Errorf(
+					"Property %s does not exist", child.property,
+				)
+			case s.data[span[0]] != '[':
+				err = fmt.Errorf(
+					"%s: is not an array", child.property,
+				)
+			default:
+				items, scanned := elements[child.property]
+				if !scanned {
+					items, err = scanArray(&scanner{data: s.data, pos: span[0]})
+					elements[child.property] = items
+				}
+				if err == nil && index >= len(items) {
+					err = fmt.Errorf(
+						"%s: Min index is 0, Max index is %d. You passed index %d", child.property, len(items), index,
+					)
+				}
+				if err == nil {
+					span = items[index]
+				}
+			}
+			if err != nil {
+				for _, i := range child.all {
+					failPath(i, err)
+				}
+				continue
+			}
+		}
+
+		for _, i := range child.ends {
+			if ok {
+				resolve(i, span, nil)
+			} else {
+				failPath(i, fmt.Errorf("Property %s does not exist", t.remaining(i, child.depth)))
+			}
+		}
+		if len(child.children) == 0 {
+			continue
+		}
+
+		switch {
+		case !ok:
+			t.fail(child, failPath, func(i int) error {
+				return fmt.Errorf("Property %s does not exist", child.property)
+			})
+		case s.data[span[0]] != '{':
+			t.fail(child, failPath, func(i int) error {
+				return fmt.Errorf("Property %s does not exist", t.remaining(i, child.depth))
+			})
+		default:
+			t.visitBytes(child, &scanner{data: s.data, pos: span[0]}, resolve)
+		}
+	}
+}
+
+// scanObject calls fn for every key of the object starting at the position
+// of the scanner, with the scanner at the value. fn reports whether it moved
+// past the value, otherwise the value is skipped.
+func scanObject(s *scanner, fn func(key string) (bool, error)) error {
+	if err := s.expect('{'); err != nil {
+		return err
+	}
+	if s.peek() == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		key, err := s.readString()
+		if err != nil {
+			return err
+		}
+		if err := s.expect(':'); err != nil {
+			return err
+		}
+		s.skipSpace()
+		skipped, err := fn(key)
+		if err != nil {
+			return err
+		}
+		if !skipped {
+			if err := s.skipValue(); err != nil {
+				return err
+			}
+		}
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			return nil
+		default:
+			return s.syntaxError()
+		}
+	}
+}
+
+// scanArray returns the positions of the elements of the array starting
+// at the position of the scanner.
+func scanArray(s *scanner) ([][2]int, error) {
+	items := make([][2]int, 0)
+	if err := s.expect('['); err != nil {
+		return nil, err
+	}
+	if s.peek() == ']' {
+		s.pos++
+		return items, nil
+	}
+	for {
+		s.skipSpace()
+		begin := s.pos
+		if err := s.skipValue(); err != nil {
+			return nil, err
+		}
+		items = append(items, [2]int{begin, s.pos})
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case ']':
+			s.pos++
+			return items, nil
+		default:
+			return nil, s.syntaxError()
+		}
+	}
+}
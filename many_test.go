@@ -0,0 +1,121 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testManyPaths = []string{
+	"action",
+	"number",
+	"pull_request.title",
+	"pull_request.user.login",
+	"pull_request.user.id",
+	"pull_request.user",
+	"pull_request.commits[0].sha",
+	"pull_request.commits[1].sha",
+	"pull_request.commits[0].files[1]",
+	"pull_request.commits[2].sha",
+	"pull_request.commits[99999999999999999999]",
+	"pull_request.user[0]",
+	"pull_request.user.missing",
+	"pull_request.missing.login",
+	"pull_request.title.length",
+	"labels.name",
+	"labels[0]",
+	"missing",
+	".pull_request..user.login",
+	"action",
+	"",
+	"..",
+}
+
+// manyPaths returns testManyPaths written with separator.
+// With "/" the dots of "escaped.key" are a part of the key.
+func manyPaths(separator string) []string {
+	paths := make([]string, 0, len(testManyPaths)+2)
+	for _, path := range testManyPaths {
+		paths = append(paths, strings.Replace(path, ".", separator, -1))
+	}
+	if separator != "." {
+		paths = append(paths, "escaped.key", "key"+separator+"slash")
+	}
+	return paths
+}
+
+func TestGetMany(t *testing.T) {
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(testPayload, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, separator := range []string{".", "/"} {
+		paths := manyPaths(separator)
+		results := GetManySep(document, paths, separator)
+		if separator == "." {
+			results = GetMany(document, paths...)
+		}
+		if len(results) != len(paths) {
+			t.Fatalf("Should return %d results. Got %d", len(paths), len(results))
+		}
+		for i, result := range results {
+			value, err := GetProperty(document, paths[i], separator)
+			if result.Path != paths[i] {
+				t.Errorf("\n[%d of %d: %s] Path should equal %s. Got %s", i+1, len(results), paths[i], paths[i], result.Path)
+			}
+			if fmt.Sprint(result.Err) != fmt.Sprint(err) {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(results), paths[i], result.Err, err)
+			}
+			if !reflect.DeepEqual(result.Value, value) {
+				t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(results), paths[i], result.Value, value)
+			}
+		}
+	}
+}
+
+func TestGetManyBytes(t *testing.T) {
+	for _, separator := range []string{".", "/"} {
+		paths := manyPaths(separator)
+		results := GetManyBytesSep(testPayload, paths, separator)
+		if separator == "." {
+			results = GetManyBytes(testPayload, paths...)
+		}
+		if len(results) != len(paths) {
+			t.Fatalf("Should return %d results. Got %d", len(paths), len(results))
+		}
+		for i, result := range results {
+			raw, err := GetBytes(testPayload, paths[i], separator)
+			if fmt.Sprint(result.Err) != fmt.Sprint(err) {
+				t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(results), paths[i], result.Err, err)
+			}
+			if string(result.Raw) != string(raw) {
+				t.Errorf("\n[%d of %d: %s] Results should equal \n\t%s \n \n\t%s", i+1, len(results), paths[i], result.Raw, raw)
+			}
+		}
+	}
+
+	duplicates := []byte(`{"a": {"b": 1}, "a": {"b": 2, "c": [3]}, "c": [1], "c": [2]}`)
+	for i, result := range GetManyBytes(duplicates, "a.b", "a.c[0]", "c[0]") {
+		raw, err := GetBytes(duplicates, result.Path)
+		if result.Err != nil || err != nil || string(result.Raw) != string(raw) {
+			t.Errorf("\n[%d: %s] Duplicate keys should resolve like GetBytes \n\t%s %v \n \n\t%s %v", i+1, result.Path, result.Raw, result.Err, raw, err)
+		}
+	}
+
+	invalid := GetManyBytes([]byte(`{"a": {"b": 1, "c": tru}, "d": 2}`), "a.b", "d")
+	if invalid[0].Err == nil || invalid[1].Err != nil || string(invalid[1].Raw) != "2" {
+		t.Error("Invalid JSON should fail the paths of the invalid object only. Got ", invalid)
+	}
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	document := setupBenchmarkDocument()
+	paths := []string{"event.type", "event.repository.name", "event.repository.owner.login", "event.commits[0].id", "event.commits[1].author.name"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetMany(document, paths...)
+	}
+}
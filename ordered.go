@@ -0,0 +1,275 @@
+package gjm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+func init() {
+	RegisterCloneFunc(reflect.TypeOf(&OrderedMap{}), func(value interface{}) interface{} {
+		return value.(*OrderedMap).Clone()
+	})
+}
+
+// OrderedMap is a JSON object which keeps the order of its keys.
+// New keys are appended, updated keys keep their position and deleted
+// keys are removed without reordering the others.
+// CRUD functions traverse OrderedMap values the same way they traverse
+// `map[string]interface{}`, so an OrderedMap can be nested in any document.
+//
+//	document := NewOrderedMap()
+//	err := json.Unmarshal(config, document) // nested objects are *OrderedMap too
+//	err = document.UpdateProperty("server.port", 8080)
+//	output, err := json.MarshalIndent(document, "", "  ")
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap creates an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		values: make(map[string]interface{}),
+	}
+}
+
+// Get returns the value of a key.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set sets the value of a key. New keys are appended.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes a key keeping the order of the others.
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order.
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Clone returns a deep copy of the map.
+func (m *OrderedMap) Clone() *OrderedMap {
+	cloned := &OrderedMap{
+		keys:   m.Keys(),
+		values: make(map[string]interface{}, len(m.values)),
+	}
+	for key, value := range m.values {
+		cloned.values[key] = cloneValue(value)
+	}
+	return cloned
+}
+
+// GetProperty returns a property. See GetProperty.
+func (m *OrderedMap) GetProperty(path string, separator_arr ...string) (interface{}, error) {
+	return getProperty(m, path, getSeparator(separator_arr))
+}
+
+// CreateProperty creates a property. See CreateProperty.
+func (m *OrderedMap) CreateProperty(path string, value interface{}, separator_arr ...string) error {
+	return createProperty(m, path, value, getSeparator(separator_arr))
+}
+
+// UpdateProperty creates or updates a property. See UpdateProperty.
+func (m *OrderedMap) UpdateProperty(path string, value interface{}, separator_arr ...string) error {
+	return updateProperty(m, path, value, getSeparator(separator_arr))
+}
+
+// DeleteProperty removes a property. See DeleteProperty.
+func (m *OrderedMap) DeleteProperty(path string, separator_arr ...string) error {
+	return deleteProperty(m, path, getSeparator(separator_arr))
+}
+
+// MarshalJSON encodes the map with its keys in order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encoded, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encoded)
+		buffer.WriteByte(':')
+		if encoded, err = json.Marshal(m.values[key]); err != nil {
+			return nil, err
+		}
+		buffer.Write(encoded)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object keeping the order of its keys.
+// Nested objects are decoded as *OrderedMap, arrays as []interface{}.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("Can not decode %v into an OrderedMap", token)
+	}
+
+	m.keys = nil
+	m.values = make(map[string]interface{})
+	return m.decode(decoder)
+}
+
+// decode reads the members of an object which opening bracket was read.
+func (m *OrderedMap) decode(decoder *json.Decoder) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		value, err := decodeOrdered(decoder)
+		if err != nil {
+			return err
+		}
+		m.Set(token.(string), value)
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := NewOrderedMap()
+		return m, m.decode(decoder)
+	case json.Delim('['):
+		items := make([]interface{}, 0)
+		for decoder.More() {
+			item, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := decoder.Token()
+		return items, err
+	}
+	return token, nil
+}
+
+// object is a JSON object traversed by the CRUD functions.
+type object interface {
+	get(key string) (interface{}, bool)
+	set(key string, value interface{})
+	del(key string)
+	clear()
+	len() int
+}
+
+// mapObject is the object of a `map[string]interface{}`.
+type mapObject map[string]interface{}
+
+func (m mapObject) get(key string) (interface{}, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapObject) set(key string, value interface{}) {
+	m[key] = value
+}
+
+func (m mapObject) del(key string) {
+	delete(m, key)
+}
+
+func (m mapObject) clear() {
+	for key := range m {
+		delete(m, key)
+	}
+}
+
+func (m mapObject) len() int {
+	return len(m)
+}
+
+func (m *OrderedMap) get(key string) (interface{}, bool) {
+	return m.Get(key)
+}
+
+func (m *OrderedMap) set(key string, value interface{}) {
+	m.Set(key, value)
+}
+
+func (m *OrderedMap) del(key string) {
+	m.Delete(key)
+}
+
+func (m *OrderedMap) clear() {
+	m.keys = nil
+	m.values = make(map[string]interface{})
+}
+
+func (m *OrderedMap) len() int {
+	return m.Len()
+}
+
+// asObject returns the object of a `map[string]interface{}` or an *OrderedMap.
+func asObject(value interface{}) (object, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return mapObject(v), true
+	case mapObject:
+		return v, true
+	case *OrderedMap:
+		if v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// newObject creates an empty object of the type of like, so properties
+// created in an OrderedMap keep their order too. It returns the object
+// and the value to store.
+func newObject(like object) (object, interface{}) {
+	if _, ok := like.(*OrderedMap); ok {
+		created := NewOrderedMap()
+		return created, created
+	}
+	created := make(map[string]interface{})
+	return mapObject(created), created
+}
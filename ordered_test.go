@@ -0,0 +1,254 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMapRoundTrip(t *testing.T) {
+	input := `{"z":1,"a":{"y":[{"k":2,"b":3}],"c":null},"m":"text"}`
+
+	document := NewOrderedMap()
+	if err := json.Unmarshal([]byte(input), document); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(document.Keys(), []string{"z", "a", "m"}) {
+		t.Errorf("Keys should be in source order, got %v", document.Keys())
+	}
+	output, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != input {
+		t.Errorf("Output should equal \n\t%s \n \n\t%s", output, input)
+	}
+
+	if err := json.Unmarshal([]byte(`[1]`), document); err == nil {
+		t.Error("Arrays can not be decoded into an OrderedMap")
+	}
+}
+
+func TestOrderedMapCRUD(t *testing.T) {
+	input := `{"z":1,"a":{"y":[{"k":2,"b":3}],"c":null},"m":"text"}`
+
+	tests := []struct {
+		name     string
+		change   func(document *OrderedMap) error
+		expected string
+		err      error
+	}{
+		{
+			name:     "update keeps position",
+			change:   func(document *OrderedMap) error { return document.UpdateProperty("z", 5) },
+			expected: `{"z":5,"a":{"y":[{"k":2,"b":3}],"c":null},"m":"text"}`,
+		},
+		{
+			name:     "create appends",
+			change:   func(document *OrderedMap) error { return document.CreateProperty("a.d", true) },
+			expected: `{"z":1,"a":{"y":[{"k":2,"b":3}],"c":null,"d":true},"m":"text"}`,
+		},
+		{
+			name: "nested creation",
+			change: func(document *OrderedMap) error {
+				if err := document.CreateProperty("new.second", 2); err != nil {
+					return err
+				}
+				return document.CreateProperty("new.first", 1)
+			},
+			expected: `{"z":1,"a":{"y":[{"k":2,"b":3}],"c":null},"m":"text","new":{"second":2,"first":1}}`,
+		},
+		{
+			name:     "array element",
+			change:   func(document *OrderedMap) error { return document.UpdateProperty("a.y[0].a", 4) },
+			expected: `{"z":1,"a":{"y":[{"k":2,"b":3,"a":4}],"c":null},"m":"text"}`,
+		},
+		{
+			name:     "append",
+			change:   func(document *OrderedMap) error { return document.UpdateProperty("a.y[+].k", 5) },
+			expected: `{"z":1,"a":{"y":[{"k":2,"b":3},{"k":5}],"c":null},"m":"text"}`,
+		},
+		{
+			name:     "delete keeps order",
+			change:   func(document *OrderedMap) error { return document.DeleteProperty("a.y[0].k") },
+			expected: `{"z":1,"a":{"y":[{"b":3}],"c":null},"m":"text"}`,
+		},
+		{
+			name:     "delete top level",
+			change:   func(document *OrderedMap) error { return document.DeleteProperty("a") },
+			expected: `{"z":1,"m":"text"}`,
+		},
+		{
+			name:     "create existing",
+			change:   func(document *OrderedMap) error { return document.CreateProperty("a.c", 1) },
+			expected: input,
+			err:      fmt.Errorf("Property a.c already exists"),
+		},
+		{
+			name:     "delete missing",
+			change:   func(document *OrderedMap) error { return document.DeleteProperty("a.x.y") },
+			expected: input,
+			err:      fmt.Errorf("Property x does not exist"),
+		},
+	}
+
+	for i, test := range tests {
+		document := NewOrderedMap()
+		if err := json.Unmarshal([]byte(input), document); err != nil {
+			t.Fatal(err)
+		}
+		err := test.change(document)
+		if fmt.Sprint(err) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, err, test.err)
+		}
+		output, _ := json.Marshal(document)
+		if string(output) != test.expected {
+			t.Errorf("\n[%d of %d: %s] Output should equal \n\t%s \n \n\t%s", i+1, len(tests), test.name, output, test.expected)
+		}
+	}
+}
+
+func TestOrderedMapNested(t *testing.T) {
+	nested := NewOrderedMap()
+	nested.Set("second", 2)
+	nested.Set("first", 1)
+	document := map[string]interface{}{"nested": nested}
+
+	if value, err := GetProperty(document, "nested.first"); err != nil || value != 1 {
+		t.Errorf("Property should be read through an OrderedMap, got %v %v", value, err)
+	}
+	if err := UpdateProperty(document, "nested.third", 3); err != nil {
+		t.Error(err)
+	}
+	if err := DeleteProperty(document, "nested.second"); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(nested.Keys(), []string{"first", "third"}) {
+		t.Errorf("Keys should equal [first third], got %v", nested.Keys())
+	}
+
+	cloned := Clone(document)
+	nested.Set("first", 10)
+	if value, _ := GetProperty(cloned, "nested.first"); value != 1 {
+		t.Errorf("Cloned OrderedMap should not change, got %v", value)
+	}
+
+	root, err := nested.GetProperty("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.(*OrderedMap).Delete("first")
+	if nested.Len() != 2 {
+		t.Error("Root property should be a copy")
+	}
+}
+
+func TestOrderedMapRollback(t *testing.T) {
+	input := `{"cfg":{"a":1,"b":2,"list":[{"x":1}]}}`
+	setup := func() map[string]interface{} {
+		cfg := NewOrderedMap()
+		if err := json.Unmarshal([]byte(`{"a":1,"b":2,"list":[{"x":1}]}`), cfg); err != nil {
+			t.Fatal(err)
+		}
+		return map[string]interface{}{"cfg": cfg}
+	}
+	encode := func(document map[string]interface{}) string {
+		output, err := json.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(output)
+	}
+
+	// a nil value deletes the property
+	changes := []struct {
+		path  string
+		value interface{}
+	}{
+		{path: "cfg.b", value: 100},
+		{path: "cfg.a"},
+		{path: "cfg.a", value: 2},
+		{path: "cfg.list[0].y", value: 2},
+		{path: "cfg.c", value: 3},
+	}
+	apply := func(update func(path string, value interface{}) error, remove func(path string) error) error {
+		for _, change := range changes {
+			var err error
+			if change.value == nil {
+				err = remove(change.path)
+			} else {
+				err = update(change.path, change.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Transaction.Rollback
+	document := setup()
+	tx := Begin(document)
+	err := apply(
+		func(path string, value interface{}) error { return tx.UpdateProperty(path, value) },
+		func(path string) error { return tx.DeleteProperty(path) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := encode(document); output != `{"cfg":{"b":100,"list":[{"x":1,"y":2}],"a":2,"c":3}}` {
+		t.Fatal("Document should be changed. Got ", output)
+	}
+	tx.Rollback()
+	if output := encode(document); output != input {
+		t.Errorf("Rollback output should equal \n\t%s \n \n\t%s", output, input)
+	}
+
+	// Failed Document.Update
+	doc := NewDocument(setup(), WithHistory(10))
+	err = doc.Update(func(tx *Tx) error {
+		err := apply(
+			func(path string, value interface{}) error { return tx.UpdateProperty(path, value) },
+			func(path string) error { return tx.DeleteProperty(path) },
+		)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed")
+	})
+	if err == nil {
+		t.Fatal("Update should fail")
+	}
+	if output := encode(doc.Clone()); output != input {
+		t.Errorf("Failed update output should equal \n\t%s \n \n\t%s", output, input)
+	}
+
+	// Document.Undo and Redo, one version per change
+	versions := []string{encode(doc.Clone())}
+	for i := range changes {
+		change := changes[i : i+1]
+		err := doc.Update(func(tx *Tx) error {
+			if change[0].value == nil {
+				return tx.DeleteProperty(change[0].path)
+			}
+			return tx.UpdateProperty(change[0].path, change[0].value)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, encode(doc.Clone()))
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		doc.Undo()
+		if output := encode(doc.Clone()); output != versions[i] {
+			t.Errorf("\n[%d of %d: Undo] Output should equal \n\t%s \n \n\t%s", i+1, len(changes), output, versions[i])
+		}
+	}
+	for i := 1; i <= len(changes); i++ {
+		doc.Redo()
+		if output := encode(doc.Clone()); output != versions[i] {
+			t.Errorf("\n[%d of %d: Redo] Output should equal \n\t%s \n \n\t%s", i, len(changes), output, versions[i])
+		}
+	}
+}
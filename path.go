@@ -0,0 +1,541 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed property path.
+//
+//	path, err := ParsePath("one.two.three[0]")
+//	path.String() // one.two.three[0]
+type Path []PathElement
+
+// PathElement is a single level of a Path: either a map key or an array index.
+type PathElement struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// pathSegment is a separator delimited part of a path: `key[0][1]`.
+type pathSegment struct {
+	key      string
+	brackets []string
+}
+
+// ParsePath parses a path into levels.
+// Characters preceded by `\` are part of a key, so keys containing the separator
+// or brackets can be addressed. Several indexes may follow a key: `matrix[0][1]`.
+//
+//	path, err := ParsePath("one.two.three[0]")
+//	path, err := ParsePath("one/two.three/four", "/")
+//	path, err := ParsePath(`servers.api\.example\.com.host`)
+func ParsePath(path string, separator_arr ...string) (Path, error) {
+	return parsePath(path, getSeparator(separator_arr), false)
+}
+
+// appendIndex is the index of `[+]` and `[-]` levels, which append to an array.
+const appendIndex = -1
+
+// parsePath parses a path. With append_tokens `[+]` and `[-]` are
+// parsed as levels with the index appendIndex.
+func parsePath(path string, separator string, append_tokens bool) (Path, error) {
+	segments, err := splitPath(path, separator)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(Path, 0, len(segments))
+	for _, segment := range segments {
+		if len(segment.key) > 0 {
+			parsed = append(parsed, PathElement{Key: segment.key})
+		}
+		for _, bracket := range segment.brackets {
+			if append_tokens && (bracket == "+" || bracket == "-") {
+				parsed = append(parsed, PathElement{Index: appendIndex, IsIndex: true})
+				continue
+			}
+			index, err := strconv.Atoi(bracket)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", segment.key, bracket),
+					"number",
+				)
+			}
+			parsed = append(parsed, PathElement{Index: index, IsIndex: true})
+		}
+	}
+	return parsed, nil
+}
+
+// String formats a path with the default separator.
+func (p Path) String() string {
+	return p.Format(".")
+}
+
+// Format formats a path with a separator. Characters of keys which
+// could be confused with the separator or an index are escaped with `\`.
+func (p Path) Format(separator string) string {
+	var b strings.Builder
+	for i, element := range p {
+		if element.IsIndex && element.Index == appendIndex {
+			b.WriteString("[+]")
+			continue
+		}
+		if element.IsIndex {
+			b.WriteString("[")
+			b.WriteString(strconv.Itoa(element.Index))
+			b.WriteString("]")
+			continue
+		}
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(escapeKey(element.Key, separator))
+	}
+	return b.String()
+}
+
+// Child returns a copy of the path extended with a key.
+func (p Path) Child(key string) Path {
+	return p.append(PathElement{Key: key})
+}
+
+// Item returns a copy of the path extended with an array index.
+func (p Path) Item(index int) Path {
+	return p.append(PathElement{Index: index, IsIndex: true})
+}
+
+func (p Path) append(element PathElement) Path {
+	child := make(Path, len(p), len(p)+1)
+	copy(child, p)
+	return append(child, element)
+}
+
+// HasPrefix reports whether the path starts with prefix.
+func (p Path) HasPrefix(prefix Path) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both paths point to the same property.
+func (p Path) Equal(other Path) bool {
+	return len(p) == len(other) && p.HasPrefix(other)
+}
+
+func escapeKey(key string, separator string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if r == '\\' || r == '[' || r == ']' || strings.ContainsRune(separator, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitPath splits a path into segments honoring `\` escapes.
+// Empty segments are skipped the same way GetProperty skips them.
+func splitPath(path string, separator string) ([]pathSegment, error) {
+	segments := make([]pathSegment, 0)
+
+	var (
+		key      strings.Builder
+		brackets []string
+	)
+	flush := func() {
+		if key.Len() > 0 || len(brackets) > 0 {
+			segments = append(segments, pathSegment{key: key.String(), brackets: brackets})
+		}
+		key.Reset()
+		brackets = nil
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\':
+			if i+1 >= len(path) {
+				return nil, fmt.Errorf("Path %s ends with an escape character", path)
+			}
+			if len(brackets) > 0 {
+				return nil, fmt.Errorf("Path %s has a key after an index", path)
+			}
+			i++
+			key.WriteByte(path[i])
+		case strings.HasPrefix(path[i:], separator):
+			flush()
+			i += len(separator) - 1
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Path %s has an unclosed bracket", path)
+			}
+			brackets = append(brackets, path[i+1:i+end])
+			i += end
+		default:
+			if len(brackets) > 0 {
+				return nil, fmt.Errorf("Path %s has a key after an index", path)
+			}
+			key.WriteByte(path[i])
+		}
+	}
+	flush()
+
+	return segments, nil
+}
+
+// setPath sets a value at path creating missing maps and arrays the same way
+// CreateProperty does: arrays are padded with nil up to the index.
+// Existing generic maps and arrays are modified in place, typed ones are
+// replaced with generic copies.
+func setPath(original_data map[string]interface{}, path Path, value interface{}) error {
+	if len(path) == 0 {
+		return fmt.Errorf("Path is empty")
+	}
+	_, err := setIn(original_data, path, 0, value)
+	return err
+}
+
+func setIn(node interface{}, path Path, level int, value interface{}) (interface{}, error) {
+	if level == len(path) {
+		return value, nil
+	}
+	element := path[level]
+
+	if element.IsIndex {
+		var items []interface{}
+		switch {
+		case node == nil:
+		case isKind(node, reflect.Slice):
+			if generic, ok := node.([]interface{}); ok {
+				items = generic
+			} else {
+				items = toSlice(node)
+			}
+		default:
+			return nil, fmt.Errorf(
+				"%s: is not an array", path[:level].String(),
+			)
+		}
+		if element.Index >= len(items) {
+			grown := make([]interface{}, element.Index+1)
+			copy(grown, items)
+			items = grown
+		}
+		child, err := setIn(items[element.Index], path, level+1, value)
+		if err != nil {
+			return nil, err
+		}
+		items[element.Index] = child
+		return items, nil
+	}
+
+	var m map[string]interface{}
+	switch {
+	case node == nil:
+		m = make(map[string]interface{})
+	case isKind(node, reflect.Map) && reflect.TypeOf(node).Key().Kind() == reflect.String:
+		if generic, ok := node.(map[string]interface{}); ok {
+			m = generic
+		} else {
+			m = make(map[string]interface{})
+			iter := reflect.ValueOf(node).MapRange()
+			for iter.Next() {
+				m[iter.Key().String()] = iter.Value().Interface()
+			}
+		}
+	default:
+		return nil, fmt.Errorf(
+			"%s: is not an object", path[:level].String(),
+		)
+	}
+	child, err := setIn(m[element.Key], path, level+1, value)
+	if err != nil {
+		return nil, err
+	}
+	m[element.Key] = child
+	return m, nil
+}
+
+// getPath returns the value at path. Typed maps and slices are traversed with reflect.
+func getPath(node interface{}, path Path) (interface{}, bool) {
+	for _, element := range path {
+		v := reflect.ValueOf(node)
+		if !v.IsValid() {
+			return nil, false
+		}
+
+		if element.IsIndex {
+			if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || element.Index >= v.Len() {
+				return nil, false
+			}
+			node = v.Index(element.Index).Interface()
+			continue
+		}
+
+		if generic, ok := node.(map[string]interface{}); ok {
+			if node, ok = generic[element.Key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		if v.Kind() != reflect.Map || !isStringMap(v) {
+			return nil, false
+		}
+		value := v.MapIndex(reflect.ValueOf(element.Key).Convert(v.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		node = value.Interface()
+	}
+	return node, true
+}
+
+// deletePath removes a map key at path. An array element at path is set to nil,
+// so positions of the following elements do not change.
+func deletePath(original_data map[string]interface{}, path Path) bool {
+	if len(path) == 0 {
+		return false
+	}
+	parent, ok := getPath(original_data, path[:len(path)-1])
+	if !ok {
+		return false
+	}
+	element := path[len(path)-1]
+	v := reflect.ValueOf(parent)
+
+	switch {
+	case element.IsIndex && v.Kind() == reflect.Slice:
+		if element.Index >= v.Len() {
+			return false
+		}
+		v.Index(element.Index).Set(reflect.Zero(v.Type().Elem()))
+		return true
+	case !element.IsIndex && v.Kind() == reflect.Map && isStringMap(v):
+		key := reflect.ValueOf(element.Key).Convert(v.Type().Key())
+		if !v.MapIndex(key).IsValid() {
+			return false
+		}
+		v.SetMapIndex(key, reflect.Value{})
+		return true
+	}
+	return false
+}
+
+// assignPath replaces an existing value at path in place.
+// Values stored in typed maps and arrays must be assignable to their element type.
+func assignPath(original_data map[string]interface{}, path Path, value interface{}) error {
+	if len(path) == 0 {
+		return fmt.Errorf("Path is empty")
+	}
+	parent, ok := getPath(original_data, path[:len(path)-1])
+	if !ok {
+		return fmt.Errorf("Property %s does not exist", path)
+	}
+	element := path[len(path)-1]
+	v := reflect.ValueOf(parent)
+
+	switch {
+	case element.IsIndex && v.Kind() == reflect.Slice && element.Index < v.Len():
+		assignable, err := assignableValue(reflect.ValueOf(value), v.Type().Elem(), path)
+		if err != nil {
+			return err
+		}
+		v.Index(element.Index).Set(assignable)
+		return nil
+	case !element.IsIndex && v.Kind() == reflect.Map && isStringMap(v):
+		assignable, err := assignableValue(reflect.ValueOf(value), v.Type().Elem(), path)
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(element.Key).Convert(v.Type().Key()), assignable)
+		return nil
+	}
+	return fmt.Errorf("Property %s does not exist", path)
+}
+
+// usesPathGrammar reports whether a path needs the grammar of ParsePath,
+// which the level parser of the CRUD functions does not read: escaped
+// characters, several indexes after a key like `matrix[0][1]`, or an index
+// after a key made of other characters than letters, digits, `_` and `-`.
+func usesPathGrammar(path string, separator string) bool {
+	if strings.IndexByte(path, '\\') >= 0 {
+		return true
+	}
+	if strings.IndexByte(path, '[') < 0 {
+		return false
+	}
+	start := 0
+	for i := 0; i < len(path); i++ {
+		switch {
+		case strings.HasPrefix(path[i:], separator):
+			i += len(separator) - 1
+			start = i + 1
+		case path[i] == '[':
+			if i > start && path[i-1] == ']' {
+				return true
+			}
+			for j := start; j < i; j++ {
+				if !isWordChar(path[j]) && path[j] != '-' {
+					return true
+				}
+			}
+			if end := strings.IndexByte(path[i:], ']'); end > 0 {
+				i += end
+			}
+		}
+	}
+	return false
+}
+
+// getPathProperty reads a property at a parsed path with the errors of GetProperty.
+func getPathProperty(original_data interface{}, path Path, separator string) (interface{}, error) {
+	value := original_data
+	for i, element := range path {
+		if element.IsIndex {
+			var err error
+			if value, err = indexSlice(value, indexedKey(path, i, separator), element.Index); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !isObject(value) {
+			return nil, fmt.Errorf("Property %s does not exist", path[i:].Format(separator))
+		}
+		var ok bool
+		if value, ok = lookupKey(value, element.Key); !ok {
+			return nil, fmt.Errorf("Property %s does not exist", path[i:i+1].Format(separator))
+		}
+	}
+	return value, nil
+}
+
+// setPathProperty sets a property at a parsed path the way CreateProperty
+// and UpdateProperty do: missing objects are created and arrays are padded
+// with nil up to the index. Objects are changed in place, arrays are copied.
+func setPathProperty(original_data object, path Path, value interface{}, separator string) error {
+	_, err := setPathIn(original_data, original_data, path, 0, value, separator)
+	return err
+}
+
+func setPathIn(node interface{}, like object, path Path, level int, value interface{}, separator string) (interface{}, error) {
+	if level == len(path) {
+		return value, nil
+	}
+	element := path[level]
+
+	if element.IsIndex {
+		var items []interface{}
+		switch {
+		case node == nil:
+		case isKind(node, reflect.Slice):
+			items = toSlice(node)
+		default:
+			return nil, fmt.Errorf(
+				"%s: is not an array", indexedKey(path, level, separator),
+			)
+		}
+		index := element.Index
+		if index == appendIndex {
+			index = len(items)
+		}
+		if index >= len(items) {
+			grown := make([]interface{}, index+1)
+			copy(grown, items)
+			items = grown
+		}
+		child, err := setPathIn(items[index], like, path, level+1, value, separator)
+		if err != nil {
+			return nil, err
+		}
+		items[index] = child
+		return items, nil
+	}
+
+	mapped_value, ok := asObject(node)
+	if !ok {
+		if node != nil {
+			return nil, fmt.Errorf(
+				"%s: is not an object", path[:level].Format(separator),
+			)
+		}
+		mapped_value, node = newObject(like)
+	}
+	current, _ := mapped_value.get(element.Key)
+	child, err := setPathIn(current, mapped_value, path, level+1, value, separator)
+	if err != nil {
+		return nil, err
+	}
+	mapped_value.set(element.Key, child)
+	return node, nil
+}
+
+// deletePathProperty removes a property at a parsed path. An array element
+// is removed shifting the following ones, the way DeleteProperty does.
+func deletePathProperty(original_data object, path Path, separator string) error {
+	if _, err := getPathProperty(original_data, path, separator); err != nil {
+		return err
+	}
+	_, err := deletePathIn(original_data, path, 0, separator)
+	return err
+}
+
+func deletePathIn(node interface{}, path Path, level int, separator string) (interface{}, error) {
+	element := path[level]
+	last := level == len(path)-1
+
+	if element.IsIndex {
+		items := toSlice(node)
+		if last {
+			return append(items[:element.Index:element.Index], items[element.Index+1:]...), nil
+		}
+		child, err := deletePathIn(items[element.Index], path, level+1, separator)
+		if err != nil {
+			return nil, err
+		}
+		items[element.Index] = child
+		return items, nil
+	}
+
+	mapped_value, ok := asObject(node)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s: is not an object", path[:level].Format(separator),
+		)
+	}
+	if last {
+		mapped_value.del(element.Key)
+		return node, nil
+	}
+	current, _ := mapped_value.get(element.Key)
+	child, err := deletePathIn(current, path, level+1, separator)
+	if err != nil {
+		return nil, err
+	}
+	mapped_value.set(element.Key, child)
+	return node, nil
+}
+
+// indexedKey returns how errors name the array indexed at level:
+// the last key of the path before it with the indexes following that key.
+func indexedKey(path Path, level int, separator string) string {
+	start := level
+	for start > 0 && path[start-1].IsIndex {
+		start--
+	}
+	if start > 0 {
+		start--
+	}
+	return path[start:level].Format(separator)
+}
@@ -0,0 +1,228 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		path      string
+		separator string
+		out       Path
+		err       bool
+	}{
+		{
+			path:      "one.two.three[0]",
+			separator: ".",
+			out: Path{
+				{Key: "one"},
+				{Key: "two"},
+				{Key: "three"},
+				{Index: 0, IsIndex: true},
+			},
+		},
+		{
+			path:      "...one...two...",
+			separator: ".",
+			out:       Path{{Key: "one"}, {Key: "two"}},
+		},
+		{
+			path:      "matrix[1][2]",
+			separator: ".",
+			out: Path{
+				{Key: "matrix"},
+				{Index: 1, IsIndex: true},
+				{Index: 2, IsIndex: true},
+			},
+		},
+		{
+			path:      "[3].name",
+			separator: ".",
+			out:       Path{{Index: 3, IsIndex: true}, {Key: "name"}},
+		},
+		{
+			path:      `servers.api\.example\.com.host`,
+			separator: ".",
+			out:       Path{{Key: "servers"}, {Key: "api.example.com"}, {Key: "host"}},
+		},
+		{
+			path:      "servers::api.example.com::host",
+			separator: "::",
+			out:       Path{{Key: "servers"}, {Key: "api.example.com"}, {Key: "host"}},
+		},
+		{
+			path:      "",
+			separator: ".",
+			out:       Path{},
+		},
+		{path: "three[-1]", separator: ".", err: true},
+		{path: "three[abc]", separator: ".", err: true},
+		{path: "three[0", separator: ".", err: true},
+		{path: "three[0]x", separator: ".", err: true},
+		{path: `three\`, separator: ".", err: true},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		out, err := ParsePath(c.path, c.separator)
+		if (err != nil) != c.err {
+			t.Errorf("\n[%d of %d: Unexpected error] \n\t%v", case_index, num_cases, err)
+			continue
+		}
+		if !c.err && !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+	}
+}
+
+func TestPathFormat(t *testing.T) {
+	cases := []struct {
+		path      Path
+		separator string
+		out       string
+	}{
+		{
+			path:      Path{{Key: "one"}, {Key: "two"}, {Index: 1, IsIndex: true}},
+			separator: ".",
+			out:       "one.two[1]",
+		},
+		{
+			path:      Path{{Key: "a.b"}, {Key: "c[0]"}, {Key: `d\e`}},
+			separator: ".",
+			out:       `a\.b.c\[0\].d\\e`,
+		},
+		{
+			path:      Path{{Key: "a:"}, {Key: "b"}},
+			separator: "::",
+			out:       `a\:::b`,
+		},
+		{
+			path:      Path{{Index: 0, IsIndex: true}, {Key: "a"}},
+			separator: ".",
+			out:       "[0].a",
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		out := c.path.Format(c.separator)
+		if out != c.out {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+		parsed, err := ParsePath(out, c.separator)
+		if err != nil || !parsed.Equal(c.path) {
+			t.Errorf("\n[%d of %d: Should parse back] \n\t%v \n \n\t%v", case_index, num_cases, parsed, err)
+		}
+	}
+}
+
+func TestPathChild(t *testing.T) {
+	parent := Path{{Key: "a"}}
+	first := parent.Child("b")
+	second := parent.Item(1)
+
+	if first.String() != "a.b" || second.String() != "a[1]" || parent.String() != "a" {
+		t.Errorf("Children should not share memory. Got %s, %s, %s", first, second, parent)
+	}
+	if !first.HasPrefix(parent) || parent.HasPrefix(first) {
+		t.Error("a.b should have prefix a")
+	}
+}
+
+func TestCRUDPathGrammar(t *testing.T) {
+	setup := func() map[string]interface{} {
+		return map[string]interface{}{
+			"matrix": []interface{}{[]interface{}{1, 2}, []interface{}{3}},
+			"servers": map[string]interface{}{
+				"api.example.com": map[string]interface{}{"port": 80},
+			},
+			"user list": []interface{}{"a", "b"},
+		}
+	}
+
+	cases := []struct {
+		op       string
+		path     string
+		value    interface{}
+		check    string
+		expected interface{}
+		err      error
+	}{
+		{op: "get", path: "matrix[0][1]", expected: 2},
+		{op: "get", path: `servers.api\.example\.com.port`, expected: 80},
+		{op: "get", path: "user list[1]", expected: "b"},
+		{op: "get", path: "matrix[0][5]", err: fmt.Errorf("matrix[0]: Min index is 0, Max index is 2. You passed index 5")},
+		{op: "get", path: "matrix[1][0][0]", err: fmt.Errorf("matrix[1][0]: is not an array")},
+		{op: "get", path: `servers.api\.example\.com.host`, err: fmt.Errorf("Property host does not exist")},
+		{op: "get", path: `servers.web\.example\.com.port`, err: fmt.Errorf(`Property web\.example\.com does not exist`)},
+		{op: "update", path: "matrix[0][1]", value: 5, check: "matrix", expected: []interface{}{[]interface{}{1, 5}, []interface{}{3}}},
+		{op: "update", path: "matrix[1][2]", value: 9, check: "matrix", expected: []interface{}{[]interface{}{1, 2}, []interface{}{3, nil, 9}}},
+		{op: "update", path: "matrix[1][+]", value: 4, check: "matrix", expected: []interface{}{[]interface{}{1, 2}, []interface{}{3, 4}}},
+		{op: "update", path: `servers.api\.example\.com.port`, value: 81, check: "servers", expected: map[string]interface{}{"api.example.com": map[string]interface{}{"port": 81}}},
+		{op: "update", path: "user list[0]", value: "c", check: "user list", expected: []interface{}{"c", "b"}},
+		{op: "update", path: "matrix[0][0].a", value: 1, err: fmt.Errorf("matrix[0][0]: is not an object")},
+		{op: "create", path: `servers.web\.example\.com.port`, value: 81, check: "servers", expected: map[string]interface{}{"api.example.com": map[string]interface{}{"port": 80}, "web.example.com": map[string]interface{}{"port": 81}}},
+		{op: "create", path: "matrix[2][1]", value: 7, check: "matrix", expected: []interface{}{[]interface{}{1, 2}, []interface{}{3}, []interface{}{nil, 7}}},
+		{op: "create", path: "matrix[0][0]", value: 7, err: fmt.Errorf("Property matrix[0][0] already exists")},
+		{op: "delete", path: "matrix[0][0]", check: "matrix", expected: []interface{}{[]interface{}{2}, []interface{}{3}}},
+		{op: "delete", path: `servers.api\.example\.com`, check: "servers", expected: map[string]interface{}{}},
+		{op: "delete", path: "matrix[1][1]", err: fmt.Errorf("matrix[1]: Min index is 0, Max index is 1. You passed index 1")},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+		document := setup()
+
+		var value interface{}
+		var err error
+		switch c.op {
+		case "get":
+			value, err = GetProperty(document, c.path)
+		case "create":
+			err = CreateProperty(document, c.path, c.value)
+		case "update":
+			err = UpdateProperty(document, c.path, c.value)
+		case "delete":
+			err = DeleteProperty(document, c.path)
+		}
+		if fmt.Sprint(err) != fmt.Sprint(c.err) {
+			t.Errorf("\n[%d of %d: %s %s] Errors should equal \n\t%v \n \n\t%v", case_index, num_cases, c.op, c.path, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(c.check) > 0 {
+			value, _ = GetProperty(document, c.check)
+		}
+		if !Equal(value, c.expected) {
+			t.Errorf("\n[%d of %d: %s %s] Results should equal \n\t%v \n \n\t%v", case_index, num_cases, c.op, c.path, value, c.expected)
+		}
+		if c.op == "get" {
+			continue
+		}
+
+		// Changes made in a transaction are undone
+		document = setup()
+		tx := Begin(document)
+		switch c.op {
+		case "create":
+			err = tx.CreateProperty(c.path, c.value)
+		case "update":
+			err = tx.UpdateProperty(c.path, c.value)
+		case "delete":
+			err = tx.DeleteProperty(c.path)
+		}
+		tx.Rollback()
+		if err != nil || !reflect.DeepEqual(document, setup()) {
+			t.Errorf("\n[%d of %d: %s %s] Rollback should restore the document. Got %v %v", case_index, num_cases, c.op, c.path, document, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package gjm
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type patternKind int
+
+const (
+	patternKey patternKind = iota
+	patternIndex
+	patternAnyIndex
+	patternRecursive
+)
+
+type patternElement struct {
+	kind  patternKind
+	key   string
+	index int
+}
+
+// Pattern is a compiled path pattern.
+//
+//	`*` in a key matches any sequence of characters: `*` matches any key, `*_at` keys ending with `_at`
+//	`?` in a key matches any single character
+//	`[*]` matches any array index
+//	`**` matches any number of levels, including none
+//
+//	pattern, err := CompilePattern("users[*].*_at")
+//	pattern, err := CompilePattern("**.email")
+//	pattern, err := CompilePattern("metrics/**", "/")
+type Pattern struct {
+	source   string
+	elements []patternElement
+}
+
+// CompilePattern parses a path pattern.
+func CompilePattern(pattern string, separator_arr ...string) (*Pattern, error) {
+	separator := getSeparator(separator_arr)
+
+	segments, err := splitPath(pattern, separator)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &Pattern{
+		source:   pattern,
+		elements: make([]patternElement, 0, len(segments)),
+	}
+	for _, segment := range segments {
+		switch segment.key {
+		case "":
+		case "**":
+			compiled.elements = append(compiled.elements, patternElement{kind: patternRecursive})
+		default:
+			compiled.elements = append(compiled.elements, patternElement{kind: patternKey, key: segment.key})
+		}
+		for _, bracket := range segment.brackets {
+			if bracket == "*" {
+				compiled.elements = append(compiled.elements, patternElement{kind: patternAnyIndex})
+				continue
+			}
+			index, err := strconv.Atoi(bracket)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf(
+					"%s must be of type %s",
+					fmt.Sprintf("%s[%s]", segment.key, bracket),
+					"number",
+				)
+			}
+			compiled.elements = append(compiled.elements, patternElement{kind: patternIndex, index: index})
+		}
+	}
+	return compiled, nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if the pattern can not be parsed.
+func MustCompilePattern(pattern string, separator_arr ...string) *Pattern {
+	compiled, err := CompilePattern(pattern, separator_arr...)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// String returns the source of the pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match reports whether the path matches the pattern.
+func (p *Pattern) Match(path Path) bool {
+	return matchElements(p.elements, path)
+}
+
+// matchPrefix reports whether the path or any of its descendants could match the pattern.
+func (p *Pattern) matchPrefix(path Path) bool {
+	return matchPrefixElements(p.elements, path)
+}
+
+// withDescendants returns a pattern matching the same paths and everything below them.
+func (p *Pattern) withDescendants() *Pattern {
+	elements := make([]patternElement, len(p.elements), len(p.elements)+1)
+	copy(elements, p.elements)
+	return &Pattern{
+		source:   p.source,
+		elements: append(elements, patternElement{kind: patternRecursive}),
+	}
+}
+
+func matchElements(elements []patternElement, path Path) bool {
+	if len(elements) == 0 {
+		return len(path) == 0
+	}
+	if elements[0].kind == patternRecursive {
+		for i := 0; i <= len(path); i++ {
+			if matchElements(elements[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || !elements[0].match(path[0]) {
+		return false
+	}
+	return matchElements(elements[1:], path[1:])
+}
+
+func matchPrefixElements(elements []patternElement, path Path) bool {
+	if len(path) == 0 {
+		return true
+	}
+	if len(elements) == 0 {
+		return false
+	}
+	if elements[0].kind == patternRecursive {
+		return true
+	}
+	if !elements[0].match(path[0]) {
+		return false
+	}
+	return matchPrefixElements(elements[1:], path[1:])
+}
+
+func (e patternElement) match(element PathElement) bool {
+	switch e.kind {
+	case patternKey:
+		return !element.IsIndex && globMatch(e.key, element.Key)
+	case patternIndex:
+		return element.IsIndex && element.Index == e.index
+	case patternAnyIndex:
+		return element.IsIndex
+	}
+	return false
+}
+
+// globMatch matches a key against a pattern with `*` and `?` wildcards.
+func globMatch(pattern string, key string) bool {
+	p, k := 0, 0
+	star, star_k := -1, 0
+	for k < len(key) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, star_k = p, k
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == key[k]):
+			p++
+			k++
+		case star >= 0:
+			star_k++
+			p, k = star+1, star_k
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
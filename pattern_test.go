@@ -0,0 +1,95 @@
+package gjm
+
+import (
+	"testing"
+)
+
+func TestPatternMatch(t *testing.T) {
+	cases := []struct {
+		pattern   string
+		separator string
+		path      string
+		out       bool
+	}{
+		{pattern: "user.email", path: "user.email", out: true},
+		{pattern: "user.email", path: "user.name", out: false},
+		{pattern: "*.email", path: "user.email", out: true},
+		{pattern: "*.email", path: "a.user.email", out: false},
+		{pattern: "**.email", path: "a.user.email", out: true},
+		{pattern: "**.email", path: "email", out: true},
+		{pattern: "**.email", path: "users[0].email", out: true},
+		{pattern: "users.*.email", path: "users[0].email", out: false},
+		{pattern: "users[*].email", path: "users[0].email", out: true},
+		{pattern: "users[1].email", path: "users[0].email", out: false},
+		{pattern: "**.*_at", path: "events[2].created_at", out: true},
+		{pattern: "**.*_at", path: "events[2].created", out: false},
+		{pattern: "user.na?e", path: "user.name", out: true},
+		{pattern: "metrics.**", path: "metrics", out: true},
+		{pattern: "metrics.**", path: "metrics.cpu[0]", out: true},
+		{pattern: "metrics.**", path: "other.cpu", out: false},
+		{pattern: "a.**.z", path: "a.b.c[0].z", out: true},
+		{pattern: "a/*.com/host", separator: "/", path: "a.api\\.example\\.com.host", out: true},
+		{pattern: "*", path: "a.b", out: false},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		pattern, err := CompilePattern(c.pattern, c.separator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, err := ParsePath(c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := pattern.Match(path); out != c.out {
+			t.Errorf("\n[%d of %d: %s should match %s: %v]", case_index, num_cases, c.pattern, c.path, c.out)
+		}
+	}
+}
+
+func TestPatternMatchPrefix(t *testing.T) {
+	pattern := MustCompilePattern("users[*].email")
+
+	for path, out := range map[string]bool{
+		"users":          true,
+		"users[1]":       true,
+		"users[1].email": true,
+		"users[1].name":  false,
+		"groups":         false,
+	} {
+		parsed, _ := ParsePath(path)
+		if pattern.matchPrefix(parsed) != out {
+			t.Errorf("%s prefix match should be %v", path, out)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	cases := map[[2]string]bool{
+		{"*", ""}:          true,
+		{"*", "abc"}:       true,
+		{"a*c", "abbbc"}:   true,
+		{"a*c", "abbb"}:    false,
+		{"*_at", "x_at"}:   true,
+		{"*a*b", "xaxxab"}: true,
+		{"?", ""}:          false,
+		{"a", "a"}:         true,
+		{"*", "*"}:         true,
+	}
+	for c, out := range cases {
+		if globMatch(c[0], c[1]) != out {
+			t.Errorf("%s should match %s: %v", c[0], c[1], out)
+		}
+	}
+}
+
+func TestCompilePatternErrors(t *testing.T) {
+	for _, pattern := range []string{"a[x]", "a[-1]", "a[", `a\`} {
+		if _, err := CompilePattern(pattern); err == nil {
+			t.Errorf("%s should not compile", pattern)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package gjm
+
+// Pick returns a new document containing only properties matching the patterns.
+// Nesting and array positions are preserved: elements which are not picked
+// are left as nil. Picked values are deep copies.
+//
+//	picked, err := Pick(document, "user.name", "user.profile.scores", "meta.*")
+func Pick(original_data map[string]interface{}, patterns ...string) (map[string]interface{}, error) {
+	compiled, err := compilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	picked := make(map[string]interface{})
+	if err := pickInto(picked, original_data, compiled); err != nil {
+		return nil, err
+	}
+	return picked, nil
+}
+
+// pickInto copies the properties matching the patterns into picked.
+func pickInto(picked map[string]interface{}, original_data map[string]interface{}, compiled []*Pattern) error {
+	var set_err error
+	err := Walk(original_data, func(path Path, value interface{}, depth int) WalkAction {
+		matched, prefix := false, false
+		for _, pattern := range compiled {
+			if pattern.Match(path) {
+				matched = true
+				break
+			}
+			prefix = prefix || pattern.matchPrefix(path)
+		}
+		if matched {
+			if set_err = setPath(picked, path, cloneValue(value)); set_err != nil {
+				return WalkStop
+			}
+			return WalkSkip
+		}
+		if !prefix {
+			return WalkSkip
+		}
+		return WalkContinue
+	})
+	if err != nil {
+		return err
+	}
+	return set_err
+}
+
+// Omit returns a deep copy of a document without properties matching the patterns.
+// Omitted array elements are removed and the following elements are shifted.
+//
+//	omitted, err := Omit(document, "user.password", "**.ssn")
+func Omit(original_data map[string]interface{}, patterns ...string) (map[string]interface{}, error) {
+	compiled, err := compilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	omitted := Clone(original_data)
+	if omitted == nil {
+		omitted = make(map[string]interface{})
+	}
+	err = Walk(omitted, func(path Path, value interface{}, depth int) WalkAction {
+		for _, pattern := range compiled {
+			if pattern.Match(path) {
+				return WalkDelete
+			}
+		}
+		return WalkContinue
+	})
+	if err != nil {
+		return nil, err
+	}
+	return omitted, nil
+}
+
+func compilePatterns(patterns []string, separator_arr ...string) ([]*Pattern, error) {
+	compiled := make([]*Pattern, len(patterns))
+	for i, pattern := range patterns {
+		var err error
+		if compiled[i], err = CompilePattern(pattern, separator_arr...); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
@@ -0,0 +1,153 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func setupProjectDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":     "John",
+			"password": "secret",
+			"profile": map[string]interface{}{
+				"scores": []int{100, 200},
+				"ssn":    "123",
+			},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"id": 1, "price": 10},
+			map[string]interface{}{"id": 2},
+			map[string]interface{}{"id": 3, "price": 30},
+		},
+		"meta": map[string]interface{}{
+			"version": "1.0",
+			"tags":    []interface{}{"a"},
+		},
+	}
+}
+
+func TestPick(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		out      map[string]interface{}
+	}{
+		{
+			patterns: []string{"user.name", "user.profile.scores", "meta.*"},
+			out: map[string]interface{}{
+				"user": map[string]interface{}{
+					"name": "John",
+					"profile": map[string]interface{}{
+						"scores": []int{100, 200},
+					},
+				},
+				"meta": map[string]interface{}{
+					"version": "1.0",
+					"tags":    []interface{}{"a"},
+				},
+			},
+		},
+		{
+			patterns: []string{"items[*].price"},
+			out: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"price": 10},
+					nil,
+					map[string]interface{}{"price": 30},
+				},
+			},
+		},
+		{
+			patterns: []string{"items[2]"},
+			out: map[string]interface{}{
+				"items": []interface{}{
+					nil,
+					nil,
+					map[string]interface{}{"id": 3, "price": 30},
+				},
+			},
+		},
+		{
+			patterns: []string{"**.ssn", "missing.path"},
+			out: map[string]interface{}{
+				"user": map[string]interface{}{
+					"profile": map[string]interface{}{
+						"ssn": "123",
+					},
+				},
+			},
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		document := setupProjectDocument()
+		out, err := Pick(document, c.patterns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(out, c.out) {
+			t.Errorf("\n[%d of %d: Results should equal] \n\t%v \n \n\t%v", case_index, num_cases, out, c.out)
+		}
+		if !reflect.DeepEqual(document, setupProjectDocument()) {
+			t.Errorf("\n[%d of %d: Original document should not change]", case_index, num_cases)
+		}
+	}
+}
+
+func TestPickCopies(t *testing.T) {
+	document := setupProjectDocument()
+	picked, _ := Pick(document, "user.profile")
+
+	UpdateProperty(picked, "user.profile.ssn", "changed")
+	if ssn, _ := GetProperty(document, "user.profile.ssn"); ssn != "123" {
+		t.Error("Picked values should be copies. Got ", ssn)
+	}
+}
+
+func TestPickWriteError(t *testing.T) {
+	compiled, err := compilePatterns([]string{"user.name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	picked := map[string]interface{}{"user": "text"}
+	err = pickInto(picked, setupProjectDocument(), compiled)
+	if !reflect.DeepEqual(err, fmt.Errorf("user: is not an object")) {
+		t.Error("Should fail on a path which can not be written. Got ", err)
+	}
+}
+
+func TestOmit(t *testing.T) {
+	document := setupProjectDocument()
+
+	out, err := Omit(document, "user.password", "**.ssn", "items[1]", "items[*].price", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name": "John",
+			"profile": map[string]interface{}{
+				"scores": []int{100, 200},
+			},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 3},
+		},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", out, expected)
+	}
+	if !reflect.DeepEqual(document, setupProjectDocument()) {
+		t.Error("Original document should not change")
+	}
+
+	if _, err = Omit(document, "a[x]"); err == nil {
+		t.Error("Should fail on invalid pattern")
+	}
+}
@@ -0,0 +1,124 @@
+package gjm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RedactAction is what Redact does with a sensitive value.
+type RedactAction int
+
+const (
+	// RedactRemove removes the property, array elements are shifted.
+	RedactRemove RedactAction = iota
+	// RedactReplace replaces the value with RedactRule.Replacement or "***".
+	RedactReplace
+	// RedactHash replaces the value with a hex encoded HMAC-SHA256 of it
+	// keyed with RedactPolicy.HashKey.
+	RedactHash
+	// RedactLast4 masks all but the last 4 characters of the value with `*`.
+	RedactLast4
+)
+
+// RedactRule pairs a path pattern with an action.
+type RedactRule struct {
+	Pattern     string
+	Action      RedactAction
+	Replacement interface{}
+}
+
+// RedactPolicy lists rules applied by Redact. The first matching rule wins.
+type RedactPolicy struct {
+	Rules   []RedactRule
+	HashKey []byte
+	// InPlace redacts the passed document instead of a deep copy.
+	InPlace bool
+}
+
+// Redact removes or masks sensitive values matching the policy rules
+// and returns the redacted document. The passed document is not changed
+// unless the policy is InPlace.
+//
+//	redacted, err := Redact(response, RedactPolicy{
+//		Rules: []RedactRule{
+//			{Pattern: "data.users[*].password", Action: RedactRemove},
+//			{Pattern: "**.ssn", Action: RedactLast4},
+//			{Pattern: "**.email", Action: RedactHash},
+//		},
+//		HashKey: key,
+//	})
+func Redact(original_data map[string]interface{}, policy RedactPolicy) (map[string]interface{}, error) {
+	sources := make([]string, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		if rule.Action == RedactHash && len(policy.HashKey) == 0 {
+			return nil, fmt.Errorf("Rule %s requires a hash key", rule.Pattern)
+		}
+		sources[i] = rule.Pattern
+	}
+	patterns, err := compilePatterns(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := original_data
+	if !policy.InPlace {
+		redacted = Clone(original_data)
+	}
+	if redacted == nil {
+		return nil, nil
+	}
+
+	err = Walk(redacted, func(path Path, value interface{}, depth int) WalkAction {
+		for i, rule := range policy.Rules {
+			if !patterns[i].Match(path) {
+				continue
+			}
+			switch rule.Action {
+			case RedactRemove:
+				return WalkDelete
+			case RedactReplace:
+				if rule.Replacement != nil {
+					return WalkReplace(rule.Replacement)
+				}
+				return WalkReplace("***")
+			case RedactHash:
+				mac := hmac.New(sha256.New, policy.HashKey)
+				mac.Write([]byte(redactString(value)))
+				return WalkReplace(hex.EncodeToString(mac.Sum(nil)))
+			case RedactLast4:
+				return WalkReplace(maskLast4(redactString(value)))
+			}
+		}
+		return WalkContinue
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+// redactString returns a string representation of a value to hash or mask.
+func redactString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	if encoded, err := json.Marshal(value); err == nil {
+		return string(encoded)
+	}
+	return fmt.Sprint(value)
+}
+
+func maskLast4(s string) string {
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
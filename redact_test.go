@@ -0,0 +1,117 @@
+package gjm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func setupRedactDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{
+					"name":     "John",
+					"password": "secret",
+					"ssn":      "123-45-6789",
+					"email":    "john@example.com",
+				},
+				map[string]interface{}{
+					"name":     "Jane",
+					"password": "hunter2",
+					"card": map[string]interface{}{
+						"number": 4111111111111111,
+					},
+				},
+			},
+			"ssn": "987",
+		},
+		"token": map[string]interface{}{"value": "abc"},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	key := []byte("key")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("john@example.com"))
+	email_hash := hex.EncodeToString(mac.Sum(nil))
+
+	document := setupRedactDocument()
+	redacted, err := Redact(document, RedactPolicy{
+		Rules: []RedactRule{
+			{Pattern: "data.users[*].password", Action: RedactRemove},
+			{Pattern: "**.ssn", Action: RedactLast4},
+			{Pattern: "**.email", Action: RedactHash},
+			{Pattern: "**.card.number", Action: RedactLast4},
+			{Pattern: "token", Action: RedactReplace},
+		},
+		HashKey: key,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"data": map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{
+					"name":  "John",
+					"ssn":   "*******6789",
+					"email": email_hash,
+				},
+				map[string]interface{}{
+					"name": "Jane",
+					"card": map[string]interface{}{
+						"number": "************1111",
+					},
+				},
+			},
+			"ssn": "***",
+		},
+		"token": "***",
+	}
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", redacted, expected)
+	}
+	if !reflect.DeepEqual(document, setupRedactDocument()) {
+		t.Error("Original document should not change")
+	}
+}
+
+func TestRedactInPlace(t *testing.T) {
+	document := setupRedactDocument()
+	redacted, err := Redact(document, RedactPolicy{
+		Rules: []RedactRule{
+			{Pattern: "**.password", Action: RedactReplace, Replacement: "[hidden]"},
+		},
+		InPlace: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password, _ := GetProperty(document, "data.users[1].password"); password != "[hidden]" {
+		t.Error("Document should be redacted in place. Got ", password)
+	}
+	if !reflect.DeepEqual(redacted, document) {
+		t.Error("In place redaction should return the passed document")
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	_, err := Redact(setupRedactDocument(), RedactPolicy{
+		Rules: []RedactRule{{Pattern: "**.email", Action: RedactHash}},
+	})
+	if !reflect.DeepEqual(err, fmt.Errorf("Rule **.email requires a hash key")) {
+		t.Error("Hash without a key should fail. Got ", err)
+	}
+
+	_, err = Redact(map[string]interface{}{"pins": []int{1234}}, RedactPolicy{
+		Rules: []RedactRule{{Pattern: "pins[*]", Action: RedactLast4}},
+	})
+	if err == nil {
+		t.Error("Masking an element of a typed array should fail")
+	}
+}
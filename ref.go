@@ -0,0 +1,216 @@
+package gjm
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Loader loads documents referenced by `$ref` from other locations.
+type Loader interface {
+	Load(location string) (interface{}, error)
+}
+
+// LoaderFunc is a function implementing Loader.
+type LoaderFunc func(location string) (interface{}, error)
+
+// Load calls f(location).
+func (f LoaderFunc) Load(location string) (interface{}, error) {
+	return f(location)
+}
+
+// Resolver replaces `{"$ref": "..."}` objects with the referenced values.
+// References have the form `location#fragment`: an empty location refers to
+// the document being resolved, other locations are loaded with the Loader
+// relative to the document containing the reference. The fragment is either
+// a JSON Pointer (`#/definitions/address`) or a path (`#definitions.address`).
+// Loaded documents are cached for the life of the Resolver.
+//
+//	resolver := NewResolver(FSLoader{FS: os.DirFS("schemas")})
+//	resolved, err := resolver.Resolve(document)
+type Resolver struct {
+	loader Loader
+	cache  map[string]interface{}
+}
+
+// NewResolver creates a Resolver. A nil loader allows local references only.
+func NewResolver(loader Loader) *Resolver {
+	return &Resolver{
+		loader: loader,
+		cache:  make(map[string]interface{}),
+	}
+}
+
+// ResolveRefs replaces local `$ref` objects of a document.
+// It is a shortcut for NewResolver(nil).Resolve(document).
+func ResolveRefs(original_data map[string]interface{}) (map[string]interface{}, error) {
+	return NewResolver(nil).Resolve(original_data)
+}
+
+// Resolve returns a copy of a document with every `$ref` object replaced with
+// a copy of the referenced value. References are resolved recursively,
+// reference cycles are reported with the chain of references.
+func (r *Resolver) Resolve(original_data map[string]interface{}) (map[string]interface{}, error) {
+	resolving := &refResolution{
+		resolver: r,
+		root:     original_data,
+	}
+	resolved, err := resolving.node(original_data, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == nil {
+		return nil, nil
+	}
+	document, ok := resolved.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Document resolves to %T", resolved)
+	}
+	return document, nil
+}
+
+func (r *Resolver) load(location string) (interface{}, error) {
+	if document, ok := r.cache[location]; ok {
+		return document, nil
+	}
+	if r.loader == nil {
+		return nil, fmt.Errorf("Reference %s: no loader for external references", location)
+	}
+	document, err := r.loader.Load(location)
+	if err != nil {
+		return nil, fmt.Errorf("Reference %s: %v", location, err)
+	}
+	r.cache[location] = document
+	return document, nil
+}
+
+type refResolution struct {
+	resolver *Resolver
+	root     map[string]interface{}
+}
+
+// node returns a copy of a value with references resolved.
+// Base is the location of the document containing the value.
+func (rr *refResolution) node(value interface{}, base string, stack []string) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		if !isStringMap(v) {
+			return cloneValue(value), nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		if ref, ok := m["$ref"].(string); ok {
+			return rr.reference(ref, base, stack)
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			resolved, err := rr.node(m[key], base, stack)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = resolved
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			resolved, err := rr.node(v.Index(i).Interface(), base, stack)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = resolved
+		}
+		return items, nil
+	}
+	return cloneValue(value), nil
+}
+
+func (rr *refResolution) reference(ref string, base string, stack []string) (interface{}, error) {
+	location, fragment := ref, ""
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		location, fragment = ref[:i], ref[i+1:]
+	}
+	if len(location) == 0 {
+		location = base
+	} else if !path.IsAbs(location) && !strings.Contains(location, "://") {
+		location = path.Join(path.Dir(base), location)
+	}
+
+	key := location + "#" + fragment
+	for i, visiting := range stack {
+		if visiting == key {
+			chain := append(append([]string{}, stack[i:]...), key)
+			return nil, fmt.Errorf("Reference cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	var document interface{} = rr.root
+	if len(location) > 0 {
+		var err error
+		if document, err = rr.resolver.load(location); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := lookupFragment(document, fragment)
+	if err != nil {
+		return nil, fmt.Errorf("Reference %s: %v", ref, err)
+	}
+	return rr.node(target, location, append(stack, key))
+}
+
+// lookupFragment returns the value a JSON Pointer or a path points to.
+func lookupFragment(document interface{}, fragment string) (interface{}, error) {
+	if len(fragment) == 0 {
+		return document, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		path, err := ParsePath(fragment)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := getPath(document, path)
+		if !ok {
+			return nil, fmt.Errorf("Property %s does not exist", fragment)
+		}
+		return value, nil
+	}
+
+	node := document
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+
+		v := reflect.ValueOf(node)
+		switch v.Kind() {
+		case reflect.Map:
+			if !isStringMap(v) {
+				return nil, fmt.Errorf("Property %s does not exist", fragment)
+			}
+			value := v.MapIndex(reflect.ValueOf(token).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, fmt.Errorf("Property %s does not exist", fragment)
+			}
+			node = value.Interface()
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= v.Len() {
+				return nil, fmt.Errorf("Property %s does not exist", fragment)
+			}
+			node = v.Index(index).Interface()
+		default:
+			return nil, fmt.Errorf("Property %s does not exist", fragment)
+		}
+	}
+	return node, nil
+}
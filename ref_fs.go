@@ -0,0 +1,32 @@
+//go:build go1.16
+// +build go1.16
+
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FSLoader loads JSON documents from a file system.
+//
+//	loader := FSLoader{FS: os.DirFS("schemas")}
+type FSLoader struct {
+	FS fs.FS
+}
+
+// Load reads and decodes a JSON document.
+func (l FSLoader) Load(location string) (interface{}, error) {
+	data, err := fs.ReadFile(l.FS, strings.TrimPrefix(path.Clean(location), "/"))
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %v", location, err)
+	}
+	return document, nil
+}
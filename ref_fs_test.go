@@ -0,0 +1,57 @@
+//go:build go1.16
+// +build go1.16
+
+package gjm
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolverExternal(t *testing.T) {
+	files := fstest.MapFS{
+		"common/address.json": {Data: []byte(`{"address": {"city": "Berlin", "zip": {"$ref": "zip.json#/code"}}}`)},
+		"common/zip.json":     {Data: []byte(`{"code": "10115"}`)},
+		"broken.json":         {Data: []byte(`{`)},
+	}
+
+	loads := 0
+	fs_loader := FSLoader{FS: files}
+	resolver := NewResolver(LoaderFunc(func(location string) (interface{}, error) {
+		loads++
+		return fs_loader.Load(location)
+	}))
+
+	document := map[string]interface{}{
+		"home": map[string]interface{}{"$ref": "common/address.json#/address"},
+		"work": map[string]interface{}{"$ref": "common/address.json#address.city"},
+	}
+	resolved, err := resolver.Resolve(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"home": map[string]interface{}{
+			"city": "Berlin",
+			"zip":  "10115",
+		},
+		"work": "Berlin",
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", resolved, expected)
+	}
+	if loads != 2 {
+		t.Errorf("Loaded documents should be cached. Loaded %d times", loads)
+	}
+
+	_, err = resolver.Resolve(map[string]interface{}{"a": map[string]interface{}{"$ref": "missing.json"}})
+	if err == nil {
+		t.Error("Should fail on missing document")
+	}
+	_, err = resolver.Resolve(map[string]interface{}{"a": map[string]interface{}{"$ref": "broken.json"}})
+	if err == nil {
+		t.Error("Should fail on invalid document")
+	}
+}
@@ -0,0 +1,95 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResolveRefs(t *testing.T) {
+	document := map[string]interface{}{
+		"definitions": map[string]interface{}{
+			"address": map[string]interface{}{
+				"city":    "Berlin",
+				"country": map[string]interface{}{"$ref": "#/definitions/country"},
+			},
+			"country": "DE",
+			"a/b~c":   1,
+		},
+		"billing":  map[string]interface{}{"$ref": "#/definitions/address"},
+		"shipping": map[string]interface{}{"$ref": "#definitions.address.city"},
+		"escaped":  map[string]interface{}{"$ref": "#/definitions/a~1b~0c"},
+		"list": []interface{}{
+			map[string]interface{}{"$ref": "#/definitions/country"},
+		},
+	}
+
+	resolved, err := ResolveRefs(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := map[string]interface{}{
+		"city":    "Berlin",
+		"country": "DE",
+	}
+	expected := map[string]interface{}{
+		"definitions": map[string]interface{}{
+			"address": address,
+			"country": "DE",
+			"a/b~c":   1,
+		},
+		"billing":  address,
+		"shipping": "Berlin",
+		"escaped":  1,
+		"list":     []interface{}{"DE"},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", resolved, expected)
+	}
+
+	UpdateProperty(resolved, "billing.city", "Paris")
+	if city, _ := GetProperty(resolved, "definitions.address.city"); city != "Berlin" {
+		t.Error("Resolved references should be copies")
+	}
+	if _, ok := document["billing"].(map[string]interface{})["$ref"]; !ok {
+		t.Error("Original document should not change")
+	}
+}
+
+func TestResolveRefsErrors(t *testing.T) {
+	cases := []struct {
+		in  map[string]interface{}
+		err error
+	}{
+		{
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "#/b"},
+				"b": map[string]interface{}{"c": map[string]interface{}{"$ref": "#/a"}},
+			},
+			err: fmt.Errorf("Reference cycle: #/b -> #/a -> #/b"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "#/missing"},
+			},
+			err: fmt.Errorf("Reference #/missing: Property /missing does not exist"),
+		},
+		{
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "other.json#/a"},
+			},
+			err: fmt.Errorf("Reference other.json: no loader for external references"),
+		},
+	}
+
+	num_cases := len(cases)
+	for i, c := range cases {
+		case_index := i + 1
+
+		_, err := ResolveRefs(c.in)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("\n[%d of %d: Errors should equal] \n\t%v \n \n\t%v", case_index, num_cases, err, c.err)
+		}
+	}
+}
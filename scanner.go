@@ -0,0 +1,232 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// scanner reads JSON values from a byte slice without decoding them.
+// Only the values it walks through are checked, skipped subtrees
+// are checked for balanced brackets and terminated strings only.
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *scanner) syntaxError() error {
+	if s.pos >= len(s.data) {
+		return fmt.Errorf("Invalid JSON: unexpected end of input")
+	}
+	return fmt.Errorf("Invalid JSON: unexpected %q at offset %d", s.data[s.pos], s.pos)
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte, 0 at the end of input.
+func (s *scanner) peek() byte {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+func (s *scanner) expect(c byte) error {
+	if s.peek() != c {
+		return s.syntaxError()
+	}
+	s.pos++
+	return nil
+}
+
+// skipString moves past a string starting at the current position
+// and reports whether the string contains escape sequences.
+func (s *scanner) skipString() (escaped bool, err error) {
+	if s.peek() != '"' {
+		return false, s.syntaxError()
+	}
+	for i := s.pos + 1; i < len(s.data); i++ {
+		switch s.data[i] {
+		case '\\':
+			escaped = true
+			i++
+		case '"':
+			s.pos = i + 1
+			return escaped, nil
+		}
+	}
+	s.pos = len(s.data)
+	return false, s.syntaxError()
+}
+
+// readKey reads an object key and reports whether it equals key.
+func (s *scanner) readKey(key string) (bool, error) {
+	s.skipSpace()
+	begin := s.pos
+	escaped, err := s.skipString()
+	if err != nil {
+		return false, err
+	}
+	if !escaped {
+		return string(s.data[begin+1:s.pos-1]) == key, nil
+	}
+	var decoded string
+	if err := json.Unmarshal(s.data[begin:s.pos], &decoded); err != nil {
+		return false, fmt.Errorf("Invalid JSON: %v at offset %d", err, begin)
+	}
+	return decoded == key, nil
+}
+
+// readString reads a string and returns its decoded value.
+func (s *scanner) readString() (string, error) {
+	s.skipSpace()
+	begin := s.pos
+	escaped, err := s.skipString()
+	if err != nil {
+		return "", err
+	}
+	if !escaped {
+		return string(s.data[begin+1 : s.pos-1]), nil
+	}
+	var decoded string
+	if err := json.Unmarshal(s.data[begin:s.pos], &decoded); err != nil {
+		return "", fmt.Errorf("Invalid JSON: %v at offset %d", err, begin)
+	}
+	return decoded, nil
+}
+
+// skipValue moves past the value starting at the current position.
+func (s *scanner) skipValue() error {
+	switch s.peek() {
+	case '"':
+		_, err := s.skipString()
+		return err
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if _, err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					s.pos++
+					return nil
+				}
+			}
+			s.pos++
+		}
+		return s.syntaxError()
+	case 0, ',', ':', '}', ']':
+		return s.syntaxError()
+	}
+
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n', ',', '}', ']':
+			return s.checkLiteral(start)
+		}
+		s.pos++
+	}
+	return s.checkLiteral(start)
+}
+
+func (s *scanner) checkLiteral(start int) error {
+	literal := s.data[start:s.pos]
+	switch string(literal) {
+	case "true", "false", "null":
+		return nil
+	}
+	if literal[0] == '-' || (literal[0] >= '0' && literal[0] <= '9') {
+		return nil
+	}
+	s.pos = start
+	return s.syntaxError()
+}
+
+// findKey moves to the value of a key of the object starting at the current position.
+// Like encoding/json the last of duplicate keys wins, so the whole object is scanned.
+func (s *scanner) findKey(key string) (bool, error) {
+	if err := s.expect('{'); err != nil {
+		return false, err
+	}
+	if s.peek() == '}' {
+		s.pos++
+		return false, nil
+	}
+	found := -1
+	for {
+		matched, err := s.readKey(key)
+		if err != nil {
+			return false, err
+		}
+		if err := s.expect(':'); err != nil {
+			return false, err
+		}
+		if matched {
+			s.skipSpace()
+			found = s.pos
+		}
+		if err := s.skipValue(); err != nil {
+			return false, err
+		}
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			if found < 0 {
+				return false, nil
+			}
+			s.pos = found
+			return true, nil
+		default:
+			return false, s.syntaxError()
+		}
+	}
+}
+
+// findIndex moves to an element of the array starting at the current position.
+// When the element does not exist it returns the length of the array.
+func (s *scanner) findIndex(index int) (bool, int, error) {
+	if err := s.expect('['); err != nil {
+		return false, 0, err
+	}
+	if s.peek() == ']' {
+		s.pos++
+		return false, 0, nil
+	}
+	for i := 0; ; i++ {
+		if i == index {
+			s.skipSpace()
+			return true, 0, nil
+		}
+		if err := s.skipValue(); err != nil {
+			return false, 0, err
+		}
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case ']':
+			s.pos++
+			return false, i + 1, nil
+		default:
+			return false, 0, s.syntaxError()
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package gjm
+
+import (
+	"testing"
+)
+
+func TestScannerSkipValue(t *testing.T) {
+	tests := []struct {
+		data string
+		end  int
+		err  bool
+	}{
+		{data: `"a\"b" , 1`, end: 6},
+		{data: `  {"a": [1, {"b": "}"}]}, 2`, end: 24},
+		{data: `-1.5e3]`, end: 6},
+		{data: `true}`, end: 4},
+		{data: `null`, end: 4},
+		{data: `[[]`, err: true},
+		{data: `"open`, err: true},
+		{data: `nope`, err: true},
+		{data: `,`, err: true},
+		{data: ``, err: true},
+	}
+	for i, test := range tests {
+		s := &scanner{data: []byte(test.data)}
+		err := s.skipValue()
+		if test.err {
+			if err == nil {
+				t.Errorf("\n[%d of %d: %s] Should fail", i+1, len(tests), test.data)
+			}
+			continue
+		}
+		if err != nil || s.pos != test.end {
+			t.Errorf("\n[%d of %d: %s] Should end at %d. Got %d, %v", i+1, len(tests), test.data, test.end, s.pos, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gjm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Iterator yields documents one at a time, see Stream and ReadNDJSON.
+//
+//	it := Stream(file, "records[*]")
+//	for it.Next() {
+//		record := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	next  func() (map[string]interface{}, error)
+	value map[string]interface{}
+	err   error
+}
+
+// Next reads the next document. It returns false at the end of the input or on error.
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.next == nil {
+		return false
+	}
+	value, err := it.next()
+	if err == io.EOF {
+		it.next = nil
+		it.value = nil
+		return false
+	}
+	if err != nil {
+		it.err = err
+		it.value = nil
+		return false
+	}
+	it.value = value
+	return true
+}
+
+// Value returns the document read by the last call to Next.
+func (it *Iterator) Value() map[string]interface{} {
+	return it.value
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Stream yields the objects matching a pattern in a JSON document read from r.
+// Only one matched object is decoded at a time and the rest of the document
+// is skipped token by token, so memory does not grow with the input.
+// Matched objects are not searched for further matches. Matched values
+// which are not objects stop the iteration with an error.
+//
+//	it := Stream(file, "records[*]")
+func Stream(r io.Reader, pattern string, separator_arr ...string) *Iterator {
+	compiled, err := CompilePattern(pattern, separator_arr...)
+	if err != nil {
+		return &Iterator{err: err}
+	}
+
+	s := &jsonStream{
+		decoder: json.NewDecoder(r),
+		pattern: compiled,
+	}
+	return &Iterator{next: s.next}
+}
+
+// ReadNDJSON yields the objects of newline delimited JSON read from r,
+// one per line. Empty lines are skipped. When patterns are passed every
+// object is projected with Pick.
+//
+//	it := ReadNDJSON(file, "timestamp", "request.path")
+func ReadNDJSON(r io.Reader, patterns ...string) *Iterator {
+	reader := bufio.NewReader(r)
+	line := 0
+
+	return &Iterator{next: func() (map[string]interface{}, error) {
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if len(data) == 0 && err == io.EOF {
+				return nil, io.EOF
+			}
+			line++
+
+			data = bytes.TrimSpace(data)
+			if len(data) == 0 {
+				if err == io.EOF {
+					return nil, io.EOF
+				}
+				continue
+			}
+
+			document := make(map[string]interface{})
+			if err := json.Unmarshal(data, &document); err != nil {
+				return nil, fmt.Errorf("Line %d: %v", line, err)
+			}
+			if len(patterns) == 0 {
+				return document, nil
+			}
+			picked, err := Pick(document, patterns...)
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %v", line, err)
+			}
+			return picked, nil
+		}
+	}}
+}
+
+// jsonStream walks a JSON document token by token.
+type jsonStream struct {
+	decoder *json.Decoder
+	pattern *Pattern
+	stack   []streamFrame
+	started bool
+}
+
+// streamFrame is an object or an array the stream is inside of.
+type streamFrame struct {
+	path  Path
+	array bool
+	index int
+}
+
+func (s *jsonStream) next() (map[string]interface{}, error) {
+	for {
+		if len(s.stack) == 0 {
+			if s.started {
+				return nil, io.EOF
+			}
+			s.started = true
+			if value, matched, err := s.value(Path{}); matched || err != nil {
+				return value, err
+			}
+			continue
+		}
+
+		frame := &s.stack[len(s.stack)-1]
+		if !s.decoder.More() {
+			// closing bracket
+			if _, err := s.decoder.Token(); err != nil {
+				return nil, err
+			}
+			s.stack = s.stack[:len(s.stack)-1]
+			continue
+		}
+
+		var path Path
+		if frame.array {
+			path = frame.path.Item(frame.index)
+			frame.index++
+		} else {
+			token, err := s.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			path = frame.path.Child(token.(string))
+		}
+		if value, matched, err := s.value(path); matched || err != nil {
+			return value, err
+		}
+	}
+}
+
+// value decodes the value at path if it matches the pattern, enters it
+// if its descendants may match and skips it otherwise.
+func (s *jsonStream) value(path Path) (map[string]interface{}, bool, error) {
+	if s.pattern.Match(path) {
+		var value interface{}
+		if err := s.decoder.Decode(&value); err != nil {
+			return nil, false, err
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("%s: is not an object", path)
+		}
+		return object, true, nil
+	}
+
+	token, err := s.decoder.Token()
+	if err != nil {
+		return nil, false, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok || delim == '}' || delim == ']' {
+		return nil, false, nil
+	}
+	if s.pattern.matchPrefix(path) {
+		s.stack = append(s.stack, streamFrame{path: path, array: delim == '['})
+		return nil, false, nil
+	}
+
+	// skip the container
+	for depth := 1; depth > 0; {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil, false, nil
+}
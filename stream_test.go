@@ -0,0 +1,83 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	input := `{
+		"meta": {"count": 3, "records": [{"id": "skipped"}]},
+		"records": [
+			{"id": 1, "tags": ["a", {"nested": [1, 2]}]},
+			{"id": 2},
+			{"id": 3, "records": [{"id": 4}]}
+		],
+		"after": [[], {}]
+	}`
+
+	tests := []struct {
+		pattern  string
+		expected []interface{}
+		err      error
+	}{
+		{pattern: "records[*]", expected: []interface{}{1.0, 2.0, 3.0}},
+		{pattern: "**.records[*]", expected: []interface{}{"skipped", 1.0, 2.0, 3.0}},
+		{pattern: "records[1]", expected: []interface{}{2.0}},
+		{pattern: "missing[*]", expected: []interface{}{}},
+		{pattern: "records[*].tags[*]", expected: []interface{}{}, err: fmt.Errorf("records[0].tags[0]: is not an object")},
+		{pattern: "records[", expected: []interface{}{}, err: fmt.Errorf("Path records[ has an unclosed bracket")},
+	}
+
+	for i, test := range tests {
+		it := Stream(strings.NewReader(input), test.pattern)
+		ids := make([]interface{}, 0)
+		for it.Next() {
+			ids = append(ids, it.Value()["id"])
+		}
+		if fmt.Sprint(it.Err()) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.pattern, it.Err(), test.err)
+		}
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("\n[%d of %d: %s] Results should equal \n\t%v \n \n\t%v", i+1, len(tests), test.pattern, ids, test.expected)
+		}
+	}
+
+	it := Stream(strings.NewReader(`{"records": [{"id": 1}, {"id": `), "records[*]")
+	if !it.Next() || it.Next() || it.Err() == nil {
+		t.Error("Truncated input should fail after the complete records")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	input := "{\"id\": 1, \"user\": {\"name\": \"John\", \"token\": \"x\"}}\n\n{\"id\": 2, \"user\": {\"name\": \"Jane\"}}\n{\"id\": 3}"
+
+	it := ReadNDJSON(strings.NewReader(input), "id", "user.name")
+	documents := make([]map[string]interface{}, 0)
+	for it.Next() {
+		documents = append(documents, it.Value())
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+
+	expected := []map[string]interface{}{
+		{"id": 1.0, "user": map[string]interface{}{"name": "John"}},
+		{"id": 2.0, "user": map[string]interface{}{"name": "Jane"}},
+		{"id": 3.0},
+	}
+	if !reflect.DeepEqual(documents, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", documents, expected)
+	}
+
+	it = ReadNDJSON(strings.NewReader("{\"id\": 1}\n[1]\n{\"id\": 3}\n"))
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if count != 1 || it.Err() == nil || !strings.HasPrefix(it.Err().Error(), "Line 2: ") {
+		t.Error("Invalid lines should stop the iteration. Got ", count, it.Err())
+	}
+}
@@ -0,0 +1,198 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	levelIndexRe    = regexp.MustCompile(`(\w+[\_]?[\-]?)+\[\d+\]{1}`)
+	levelPropertyRe = regexp.MustCompile(`(\w+[\_]?[\-]?)+`)
+	levelNumberRe   = regexp.MustCompile(`\[\d+\]{1}`)
+)
+
+// Transaction records every change made through it to a document,
+// so the document can be restored to its exact prior state with Rollback.
+// A Transaction is not safe for concurrent use, see Document for that.
+//
+//	tx := Begin(document)
+//	if err := tx.UpdateProperty("one.two", 2); err != nil {
+//		tx.Rollback()
+//		return err
+//	}
+//	tx.Commit()
+type Transaction struct {
+	data   map[string]interface{}
+	undo   []undoEntry
+	closed bool
+}
+
+// undoEntry holds the content a map or an OrderedMap had before a change.
+type undoEntry struct {
+	data   object
+	keys   []string
+	values map[string]interface{}
+}
+
+// Begin starts a transaction over a document.
+func Begin(original_data map[string]interface{}) *Transaction {
+	return &Transaction{
+		data: original_data,
+	}
+}
+
+// GetProperty returns a property. See GetProperty.
+func (t *Transaction) GetProperty(path string, separator_arr ...string) (interface{}, error) {
+	return GetProperty(t.data, path, separator_arr...)
+}
+
+// CreateProperty creates a property and records how to undo it. See CreateProperty.
+func (t *Transaction) CreateProperty(path string, value interface{}, separator_arr ...string) error {
+	if err := t.record(path, separator_arr); err != nil {
+		return err
+	}
+	return CreateProperty(t.data, path, value, separator_arr...)
+}
+
+// UpdateProperty updates a property and records how to undo it. See UpdateProperty.
+func (t *Transaction) UpdateProperty(path string, value interface{}, separator_arr ...string) error {
+	if err := t.record(path, separator_arr); err != nil {
+		return err
+	}
+	return UpdateProperty(t.data, path, value, separator_arr...)
+}
+
+// DeleteProperty removes a property and records how to undo it. See DeleteProperty.
+func (t *Transaction) DeleteProperty(path string, separator_arr ...string) error {
+	if err := t.record(path, separator_arr); err != nil {
+		return err
+	}
+	return DeleteProperty(t.data, path, separator_arr...)
+}
+
+// Commit keeps the changes and closes the transaction.
+func (t *Transaction) Commit() error {
+	if t.closed {
+		return fmt.Errorf("Transaction is already closed")
+	}
+	t.closed = true
+	t.undo = nil
+	return nil
+}
+
+// Rollback undoes every change made through the transaction, failed ones
+// included, and closes the transaction.
+func (t *Transaction) Rollback() error {
+	if t.closed {
+		return fmt.Errorf("Transaction is already closed")
+	}
+	t.closed = true
+	restoreUndo(t.undo)
+	t.undo = nil
+	return nil
+}
+
+// record saves the maps a change of path may modify.
+func (t *Transaction) record(path string, separator_arr []string) error {
+	if t.closed {
+		return fmt.Errorf("Transaction is already closed")
+	}
+	t.undo = append(t.undo, snapshotPath(t.data, path, getSeparator(separator_arr))...)
+	return nil
+}
+
+// snapshotPath returns shallow copies of every map and OrderedMap along a path.
+// CRUD functions only assign and delete keys of these objects: arrays are
+// always reallocated, so restoring the objects restores the document.
+// A level `property[index]` reaches an element of an array, or the object
+// itself when the property is not an array: CRUD functions write it then.
+func snapshotPath(original_data map[string]interface{}, path string, separator string) []undoEntry {
+	var node object = mapObject(original_data)
+	entries := []undoEntry{newUndoEntry(node)}
+
+	if usesPathGrammar(path, separator) {
+		parsed, _ := parsePath(path, separator, true)
+		var value interface{} = node
+		for _, element := range parsed {
+			if element.IsIndex {
+				if !isKind(value, reflect.Slice) || element.Index < 0 || element.Index >= reflect.ValueOf(value).Len() {
+					break
+				}
+				value = reflect.ValueOf(value).Index(element.Index).Interface()
+			} else {
+				mapped_value, ok := asObject(value)
+				if !ok {
+					break
+				}
+				if value, ok = mapped_value.get(element.Key); !ok {
+					break
+				}
+			}
+			if mapped_value, ok := asObject(value); ok {
+				entries = append(entries, newUndoEntry(mapped_value))
+			}
+		}
+		return entries
+	}
+
+	for _, level := range splitLevels(resolveAppendTokens(mapObject(original_data), path, separator), separator) {
+		property := level
+		index := -1
+		if matched := levelIndexRe.FindString(level); len(matched) > 0 {
+			property = levelPropertyRe.FindString(level)
+			index, _ = strconv.Atoi(strings.Trim(levelNumberRe.FindString(level), "[]"))
+		}
+
+		value, ok := node.get(property)
+		if !ok {
+			break
+		}
+		if index >= 0 && isKind(value, reflect.Slice) {
+			items := toSlice(value)
+			if index >= len(items) {
+				break
+			}
+			value = items[index]
+		}
+
+		mapped_value, ok := asObject(value)
+		if !ok {
+			break
+		}
+		entries = append(entries, newUndoEntry(mapped_value))
+		node = mapped_value
+	}
+	return entries
+}
+
+func newUndoEntry(data object) undoEntry {
+	entry := undoEntry{data: data, values: make(map[string]interface{}, data.len())}
+	switch d := data.(type) {
+	case mapObject:
+		for key, value := range d {
+			entry.keys = append(entry.keys, key)
+			entry.values[key] = value
+		}
+	case *OrderedMap:
+		entry.keys = d.Keys()
+		for key, value := range d.values {
+			entry.values[key] = value
+		}
+	}
+	return entry
+}
+
+// restoreUndo restores objects in reverse order of recording.
+// OrderedMap keys get their order back.
+func restoreUndo(entries []undoEntry) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		entry.data.clear()
+		for _, key := range entry.keys {
+			entry.data.set(key, entry.values[key])
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package gjm
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	documents := []func() map[string]interface{}{
+		setupDocument,
+		setupDocument_I,
+		setupDocument_II,
+		setupDocument_III,
+	}
+	steps := []func(tx *Transaction) error{
+		func(tx *Transaction) error { return tx.UpdateProperty("one.two.three[1]", "x") },
+		func(tx *Transaction) error { return tx.UpdateProperty("one[1].two[0].five", "x") },
+		func(tx *Transaction) error { return tx.UpdateProperty("one[3].three[0].four.nine", "x") },
+		func(tx *Transaction) error { return tx.CreateProperty("one.two.four[5].a", "x") },
+		func(tx *Transaction) error { return tx.CreateProperty("a.b.c[2]", "x") },
+		func(tx *Transaction) error { return tx.UpdateProperty("one.four.five.six[0]", "x") },
+		func(tx *Transaction) error { return tx.UpdateProperty("one.two.three[+]", "x") },
+		func(tx *Transaction) error { return tx.DeleteProperty("one[0].map_a[1]") },
+		func(tx *Transaction) error { return tx.DeleteProperty("one[2].two[1].eight") },
+		func(tx *Transaction) error { return tx.DeleteProperty("request.headers") },
+		func(tx *Transaction) error { return tx.DeleteProperty("one.four") },
+		func(tx *Transaction) error { return tx.UpdateProperty("one", "x") },
+		func(tx *Transaction) error { return tx.DeleteProperty(".") },
+	}
+
+	for i, setup := range documents {
+		document := setup()
+		one := document["one"]
+
+		tx := Begin(document)
+		for _, step := range steps {
+			step(tx)
+		}
+		if reflect.DeepEqual(document, setup()) {
+			t.Fatalf("[%d: Document should be changed]", i+1)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(document, setup()) {
+			t.Errorf("\n[%d: Document should be restored] \n\t%v \n \n\t%v", i+1, document, setup())
+		}
+		if one != nil && reflect.ValueOf(document["one"]).Pointer() != reflect.ValueOf(one).Pointer() {
+			t.Errorf("[%d: Nested values should be the same]", i+1)
+		}
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	document := setupDocument()
+	tx := Begin(document)
+
+	if err := tx.UpdateProperty("one.two.three[0]", 100); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := tx.GetProperty("one.two.three[0]"); value != 100 {
+		t.Error("Changes should be visible inside the transaction. Got ", value)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := GetProperty(document, "one.two.three[0]"); value != 100 {
+		t.Error("Changes should be kept. Got ", value)
+	}
+
+	closed := fmt.Errorf("Transaction is already closed")
+	if err := tx.Rollback(); !reflect.DeepEqual(err, closed) {
+		t.Error("Closed transaction should not roll back. Got ", err)
+	}
+	if err := tx.Commit(); !reflect.DeepEqual(err, closed) {
+		t.Error("Closed transaction should not commit. Got ", err)
+	}
+	if err := tx.UpdateProperty("one", 1); !reflect.DeepEqual(err, closed) {
+		t.Error("Closed transaction should not change the document. Got ", err)
+	}
+	if value, _ := GetProperty(document, "one.two.three[0]"); value != 100 {
+		t.Error("Closed transaction should not change the document. Got ", value)
+	}
+}
+
+// randomChange describes a random create, update or delete of a path made of
+// keys, indexes and append tokens, applied with the given functions.
+type randomChange struct {
+	op    int
+	path  string
+	value interface{}
+}
+
+func newRandomChange(r *rand.Rand) randomChange {
+	keys := []string{"a", "b", "c", "d", "z"}
+	levels := make([]string, 1+r.Intn(4))
+	for i := range levels {
+		levels[i] = keys[r.Intn(len(keys))]
+		switch r.Intn(4) {
+		case 0:
+			levels[i] += fmt.Sprintf("[%d]", r.Intn(3))
+		case 1:
+			levels[i] += "[+]"
+		}
+	}
+
+	values := []func() interface{}{
+		func() interface{} { return r.Intn(100) },
+		func() interface{} { return map[string]interface{}{"z": r.Intn(100)} },
+		func() interface{} { return []interface{}{r.Intn(100), map[string]interface{}{"c": 1}} },
+	}
+	return randomChange{
+		op:    r.Intn(3),
+		path:  strings.Join(levels, "."),
+		value: values[r.Intn(len(values))](),
+	}
+}
+
+func (c randomChange) apply(create, update func(path string, value interface{}) error, remove func(path string) error) error {
+	switch c.op {
+	case 0:
+		return create(c.path, c.value)
+	case 1:
+		return update(c.path, c.value)
+	}
+	return remove(c.path)
+}
+
+func setupRandomDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"a": map[string]interface{}{
+			"c": map[string]interface{}{"z": 2},
+			"b": []interface{}{map[string]interface{}{"d": 1}, 2},
+		},
+		"d": []interface{}{1, map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+		"z": "text",
+	}
+}
+
+func TestTransactionRollbackRandom(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		document := setupRandomDocument()
+
+		tx := Begin(document)
+		changes := make([]string, 0)
+		for step := 0; step < 8; step++ {
+			change := newRandomChange(r)
+			change.apply(
+				func(path string, value interface{}) error { return tx.CreateProperty(path, value) },
+				func(path string, value interface{}) error { return tx.UpdateProperty(path, value) },
+				func(path string) error { return tx.DeleteProperty(path) },
+			)
+			changes = append(changes, fmt.Sprint(change.op, " ", change.path))
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(document, setupRandomDocument()) {
+			t.Errorf("\n[seed %d: Document should be restored after %v] \n\t%v \n \n\t%v", seed, changes, document, setupRandomDocument())
+		}
+	}
+}
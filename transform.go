@@ -0,0 +1,87 @@
+package gjm
+
+import (
+	"reflect"
+)
+
+// Rule pairs a path pattern with a function applied to every matching value.
+type Rule struct {
+	Pattern string
+	Func    func(value interface{}) (interface{}, error)
+}
+
+// Transform applies rules to a document in a single walk and returns paths
+// of the values which were changed. Every rule matching a path is applied
+// in order, the result of a rule is passed to the next one. Children of a
+// changed value are walked after the change.
+// The first error stops the walk, changes made before it are kept.
+//
+//	touched, err := Transform(document,
+//		Rule{Pattern: "**.email", Func: func(v interface{}) (interface{}, error) {
+//			if s, ok := v.(string); ok {
+//				return strings.ToLower(s), nil
+//			}
+//			return v, nil
+//		}},
+//	)
+func Transform(original_data map[string]interface{}, rules ...Rule) (touched []string, err error) {
+	sources := make([]string, len(rules))
+	for i, rule := range rules {
+		sources[i] = rule.Pattern
+	}
+	patterns, err := compilePatterns(sources)
+	if err != nil {
+		return
+	}
+
+	touched = make([]string, 0)
+	walk_err := Walk(original_data, func(path Path, value interface{}, depth int) WalkAction {
+		result := value
+		for i, rule := range rules {
+			if !patterns[i].Match(path) {
+				continue
+			}
+			if result, err = rule.Func(result); err != nil {
+				return WalkStop
+			}
+		}
+		if sameValue(value, result) {
+			return WalkContinue
+		}
+		touched = append(touched, path.String())
+		return WalkReplace(result)
+	})
+	if err == nil {
+		err = walk_err
+	}
+	return
+}
+
+// MapValues applies a function to every value matching a pattern.
+// It is a shortcut for Transform with a single rule.
+//
+//	touched, err := MapValues(document, "metrics.**", roundFloats)
+func MapValues(original_data map[string]interface{}, pattern string, fn func(value interface{}) (interface{}, error)) ([]string, error) {
+	return Transform(original_data, Rule{Pattern: pattern, Func: fn})
+}
+
+// sameValue reports whether a value was left unchanged.
+// Maps and slices are compared by identity first to avoid deep comparison of subtrees.
+func sameValue(a, b interface{}) bool {
+	a_value, b_value := reflect.ValueOf(a), reflect.ValueOf(b)
+	if a_value.IsValid() && b_value.IsValid() && a_value.Type() == b_value.Type() {
+		switch a_value.Kind() {
+		case reflect.Map, reflect.Slice:
+			if a_value.Pointer() == b_value.Pointer() && a_value.Len() == b_value.Len() {
+				return true
+			}
+		}
+	}
+	if a_value.IsValid() != b_value.IsValid() {
+		return false
+	}
+	if a_value.IsValid() && a_value.Type() != b_value.Type() {
+		return false
+	}
+	return Equal(a, b)
+}
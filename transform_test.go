@@ -0,0 +1,123 @@
+package gjm
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransform(t *testing.T) {
+	document := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{
+				"email":      "John@Example.com",
+				"created_at": "2020-01-02T03:04:05Z",
+			},
+			map[string]interface{}{
+				"email": "jane@example.com",
+			},
+		},
+		"metrics": map[string]interface{}{
+			"cpu":    1.26,
+			"memory": []interface{}{1.5, 2.44},
+			"name":   "host",
+		},
+		"rate": 1.26,
+	}
+
+	lower := Rule{Pattern: "**.email", Func: func(v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return strings.ToLower(s), nil
+		}
+		return v, nil
+	}}
+	times := Rule{Pattern: "**.*_at", Func: func(v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return time.Parse(time.RFC3339, s)
+		}
+		return v, nil
+	}}
+	round := Rule{Pattern: "metrics.**", Func: func(v interface{}) (interface{}, error) {
+		if f, ok := v.(float64); ok {
+			return math.Round(f*10) / 10, nil
+		}
+		return v, nil
+	}}
+
+	touched, err := Transform(document, lower, times, round)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected_touched := []string{
+		"metrics.cpu",
+		"metrics.memory[1]",
+		"users[0].created_at",
+		"users[0].email",
+	}
+	if !reflect.DeepEqual(touched, expected_touched) {
+		t.Errorf("Touched paths should equal \n\t%v \n \n\t%v", touched, expected_touched)
+	}
+
+	expected := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{
+				"email":      "john@example.com",
+				"created_at": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			map[string]interface{}{
+				"email": "jane@example.com",
+			},
+		},
+		"metrics": map[string]interface{}{
+			"cpu":    1.3,
+			"memory": []interface{}{1.5, 2.4},
+			"name":   "host",
+		},
+		"rate": 1.26,
+	}
+	if !reflect.DeepEqual(document, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", document, expected)
+	}
+}
+
+func TestTransformChainsRules(t *testing.T) {
+	document := map[string]interface{}{"n": 1}
+	add := Rule{Pattern: "n", Func: func(v interface{}) (interface{}, error) {
+		return v.(int) + 1, nil
+	}}
+	double := Rule{Pattern: "*", Func: func(v interface{}) (interface{}, error) {
+		return v.(int) * 2, nil
+	}}
+
+	if _, err := Transform(document, add, double); err != nil {
+		t.Fatal(err)
+	}
+	if document["n"] != 4 {
+		t.Error("Rules should be applied in order. Got ", document["n"])
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	document := map[string]interface{}{"a": 1, "b": 2}
+
+	touched, err := MapValues(document, "*", func(v interface{}) (interface{}, error) {
+		if v == 2 {
+			return nil, fmt.Errorf("bad value")
+		}
+		return 10, nil
+	})
+	if !reflect.DeepEqual(err, fmt.Errorf("bad value")) {
+		t.Error("Should return rule error. Got ", err)
+	}
+	if !reflect.DeepEqual(touched, []string{"a"}) || document["a"] != 10 || document["b"] != 2 {
+		t.Errorf("Changes before the error should be kept. Got %v, %v", touched, document)
+	}
+
+	if _, err = MapValues(document, "a[x]", nil); err == nil {
+		t.Error("Should fail on invalid pattern")
+	}
+}
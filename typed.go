@@ -0,0 +1,106 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// GetString returns a string property.
+//
+//	name, err := GetString(document, "user.name")
+func GetString(original_data map[string]interface{}, path string, separator_arr ...string) (string, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return "", err
+	}
+	if !isKind(value, reflect.String) || reflect.TypeOf(value) == json_number_type {
+		return "", fmt.Errorf("%s must be of type %s", path, "string")
+	}
+	return reflect.ValueOf(value).String(), nil
+}
+
+// GetBool returns a boolean property.
+//
+//	active, err := GetBool(document, "user.active")
+func GetBool(original_data map[string]interface{}, path string, separator_arr ...string) (bool, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return false, err
+	}
+	if !isKind(value, reflect.Bool) {
+		return false, fmt.Errorf("%s must be of type %s", path, "bool")
+	}
+	return reflect.ValueOf(value).Bool(), nil
+}
+
+// GetInt64 returns an integer property. Numbers of any Go type and
+// json.Number are accepted when their value is an integer fitting in an int64,
+// so `json.Number("9007199254740993")` and `float64(2)` both convert exactly.
+//
+//	id, err := GetInt64(document, "user.id")
+func GetInt64(original_data map[string]interface{}, path string, separator_arr ...string) (int64, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return 0, err
+	}
+	if number, ok := value.(json.Number); ok {
+		if i, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	// json.Number is parsed exactly, exponents and fractions like 1e3 or 2.0 included
+	number, ok := toRat(reflect.ValueOf(value))
+	if !ok || number == nil || !number.IsInt() || !number.Num().IsInt64() {
+		return 0, fmt.Errorf("%s must be of type %s", path, "int64")
+	}
+	return number.Num().Int64(), nil
+}
+
+// GetUint64 returns a non-negative integer property, see GetInt64.
+//
+//	id, err := GetUint64(document, "tweet.id")
+func GetUint64(original_data map[string]interface{}, path string, separator_arr ...string) (uint64, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return 0, err
+	}
+	if number, ok := value.(json.Number); ok {
+		if i, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	// json.Number is parsed exactly, exponents and fractions like 1e3 or 2.0 included
+	number, ok := toRat(reflect.ValueOf(value))
+	if !ok || number == nil || !number.IsInt() || !number.Num().IsUint64() {
+		return 0, fmt.Errorf("%s must be of type %s", path, "uint64")
+	}
+	return number.Num().Uint64(), nil
+}
+
+// GetFloat64 returns a number property as a float64.
+// json.Number values are rounded to the nearest float64.
+//
+//	price, err := GetFloat64(document, "item.price")
+func GetFloat64(original_data map[string]interface{}, path string, separator_arr ...string) (float64, error) {
+	value, err := GetProperty(original_data, path, separator_arr...)
+	if err != nil {
+		return 0, err
+	}
+	if isKind(value, reflect.Float32) || isKind(value, reflect.Float64) {
+		return reflect.ValueOf(value).Float(), nil
+	}
+	if number, ok := value.(json.Number); ok {
+		if f, err := strconv.ParseFloat(string(number), 64); err == nil {
+			return f, nil
+		}
+		return 0, fmt.Errorf("%s must be of type %s", path, "float64")
+	}
+	number, ok := toRat(reflect.ValueOf(value))
+	if !ok || number == nil {
+		return 0, fmt.Errorf("%s must be of type %s", path, "float64")
+	}
+	f, _ := number.Float64()
+	return f, nil
+}
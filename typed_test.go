@@ -0,0 +1,110 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTypedGetters(t *testing.T) {
+	document := map[string]interface{}{
+		"id":       json.Number("9007199254740993"),
+		"big":      json.Number("18446744073709551615"),
+		"negative": json.Number("-5"),
+		"exponent": json.Number("1e3"),
+		"fraction": json.Number("1.5"),
+		"decimal":  json.Number("9007199254740993.0"),
+		"large":    json.Number("1.8446744073709551615e19"),
+		"overflow": json.Number("9223372036854775808.0"),
+		"huge":     json.Number("1e1000000"),
+		"float":    2.0,
+		"int":      7,
+		"name":     "John",
+		"active":   true,
+	}
+
+	tests := []struct {
+		name     string
+		get      func(path string) (interface{}, error)
+		path     string
+		expected interface{}
+		err      error
+	}{
+		{name: "int64", get: int64Getter(document), path: "id", expected: int64(9007199254740993)},
+		{name: "int64", get: int64Getter(document), path: "exponent", expected: int64(1000)},
+		{name: "int64", get: int64Getter(document), path: "float", expected: int64(2)},
+		{name: "int64", get: int64Getter(document), path: "int", expected: int64(7)},
+		{name: "int64", get: int64Getter(document), path: "decimal", expected: int64(9007199254740993)},
+		{name: "int64", get: int64Getter(document), path: "overflow", err: fmt.Errorf("overflow must be of type int64")},
+		{name: "int64", get: int64Getter(document), path: "big", err: fmt.Errorf("big must be of type int64")},
+		{name: "int64", get: int64Getter(document), path: "fraction", err: fmt.Errorf("fraction must be of type int64")},
+		{name: "int64", get: int64Getter(document), path: "huge", err: fmt.Errorf("huge must be of type int64")},
+		{name: "int64", get: int64Getter(document), path: "name", err: fmt.Errorf("name must be of type int64")},
+		{name: "int64", get: int64Getter(document), path: "missing", err: fmt.Errorf("Property missing does not exist")},
+		{name: "uint64", get: uint64Getter(document), path: "big", expected: uint64(18446744073709551615)},
+		{name: "uint64", get: uint64Getter(document), path: "decimal", expected: uint64(9007199254740993)},
+		{name: "uint64", get: uint64Getter(document), path: "large", expected: uint64(18446744073709551615)},
+		{name: "uint64", get: uint64Getter(document), path: "overflow", expected: uint64(9223372036854775808)},
+		{name: "uint64", get: uint64Getter(document), path: "fraction", err: fmt.Errorf("fraction must be of type uint64")},
+		{name: "uint64", get: uint64Getter(document), path: "negative", err: fmt.Errorf("negative must be of type uint64")},
+		{name: "uint64", get: uint64Getter(document), path: "huge", err: fmt.Errorf("huge must be of type uint64")},
+		{name: "float64", get: float64Getter(document), path: "fraction", expected: 1.5},
+		{name: "float64", get: float64Getter(document), path: "int", expected: 7.0},
+		{name: "float64", get: float64Getter(document), path: "active", err: fmt.Errorf("active must be of type float64")},
+		{name: "string", get: stringGetter(document), path: "name", expected: "John"},
+		{name: "string", get: stringGetter(document), path: "id", err: fmt.Errorf("id must be of type string")},
+		{name: "bool", get: boolGetter(document), path: "active", expected: true},
+		{name: "bool", get: boolGetter(document), path: "int", err: fmt.Errorf("int must be of type bool")},
+	}
+
+	for i, test := range tests {
+		value, err := test.get(test.path)
+		if fmt.Sprint(err) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, test.path, err, test.err)
+		}
+		if err == nil && value != test.expected {
+			t.Errorf("\n[%d of %d: %s %s] Values should equal \n\t%#v \n \n\t%#v", i+1, len(tests), test.name, test.path, value, test.expected)
+		}
+	}
+}
+
+func TestTypedGettersLargeExponents(t *testing.T) {
+	document := map[string]interface{}{
+		"positive": json.Number("1e1000000"),
+		"negative": json.Number("-1e-1000000"),
+	}
+
+	start := time.Now()
+	for _, path := range []string{"positive", "negative"} {
+		if _, err := GetInt64(document, path); err == nil {
+			t.Errorf("%s should not be an int64", path)
+		}
+		if _, err := GetUint64(document, path); err == nil {
+			t.Errorf("%s should not be an uint64", path)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Numbers with large exponents should not be expanded, took %v", elapsed)
+	}
+}
+
+func int64Getter(document map[string]interface{}) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) { return GetInt64(document, path) }
+}
+
+func uint64Getter(document map[string]interface{}) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) { return GetUint64(document, path) }
+}
+
+func float64Getter(document map[string]interface{}) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) { return GetFloat64(document, path) }
+}
+
+func stringGetter(document map[string]interface{}) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) { return GetString(document, path) }
+}
+
+func boolGetter(document map[string]interface{}) func(path string) (interface{}, error) {
+	return func(path string) (interface{}, error) { return GetBool(document, path) }
+}
@@ -0,0 +1,206 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type walkOp int
+
+const (
+	walkContinue walkOp = iota
+	walkSkip
+	walkStop
+	walkDelete
+	walkReplace
+)
+
+// WalkAction tells Walk how to proceed after visiting a value.
+type WalkAction struct {
+	op    walkOp
+	value interface{}
+}
+
+var (
+	// WalkContinue visits the children of the value.
+	WalkContinue = WalkAction{op: walkContinue}
+	// WalkSkip does not visit the children of the value.
+	WalkSkip = WalkAction{op: walkSkip}
+	// WalkStop stops the walk. Changes made so far are kept.
+	WalkStop = WalkAction{op: walkStop}
+	// WalkDelete removes the value from its map or array.
+	WalkDelete = WalkAction{op: walkDelete}
+)
+
+// WalkReplace replaces the value and visits the children of the replacement.
+func WalkReplace(value interface{}) WalkAction {
+	return WalkAction{op: walkReplace, value: value}
+}
+
+// WalkFunc is called by Walk for every value of a document.
+// Depth is the number of levels in path: top level properties have depth 1.
+type WalkFunc func(path Path, value interface{}, depth int) WalkAction
+
+// Walk visits every property of a document, arrays elements included,
+// parents before children and map keys in sorted order.
+// The callback controls the walk with the returned action and can replace
+// or delete values in place. Elements following a deleted array element
+// keep their original indexes in paths until the walk is over.
+//
+//	err := Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+//		if path.String() == "one.two" {
+//			return WalkSkip
+//		}
+//		return WalkContinue
+//	})
+func Walk(original_data map[string]interface{}, fn WalkFunc) error {
+	w := &walker{fn: fn}
+	_, _, err := w.children(Path{}, reflect.ValueOf(original_data))
+	return err
+}
+
+type walker struct {
+	fn      WalkFunc
+	stopped bool
+}
+
+// visit calls the callback for a value and walks its children.
+// Returns the value to store in the parent and whether it has to be stored.
+func (w *walker) visit(path Path, v reflect.Value) (result reflect.Value, changed bool, deleted bool, err error) {
+	var value interface{}
+	if v.IsValid() && v.CanInterface() {
+		value = v.Interface()
+	}
+
+	action := w.fn(path, value, len(path))
+	switch action.op {
+	case walkStop:
+		w.stopped = true
+		return v, false, false, nil
+	case walkSkip:
+		return v, false, false, nil
+	case walkDelete:
+		return v, false, true, nil
+	case walkReplace:
+		v = reflect.ValueOf(action.value)
+		changed = true
+	}
+
+	children, children_changed, err := w.children(path, v)
+	if err != nil {
+		return v, false, false, err
+	}
+	return children, changed || children_changed, false, nil
+}
+
+// children walks the children of a map or an array.
+func (w *walker) children(path Path, v reflect.Value) (reflect.Value, bool, error) {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return v, false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if !isStringMap(v) {
+			return v, false, nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if w.stopped {
+				break
+			}
+			map_key := reflect.ValueOf(key).Convert(v.Type().Key())
+			child_path := path.Child(key)
+
+			result, changed, deleted, err := w.visit(child_path, v.MapIndex(map_key))
+			if err != nil {
+				return v, false, err
+			}
+			if deleted {
+				v.SetMapIndex(map_key, reflect.Value{})
+			} else if changed {
+				value, err := assignableValue(result, v.Type().Elem(), child_path)
+				if err != nil {
+					return v, false, err
+				}
+				v.SetMapIndex(map_key, value)
+			}
+		}
+		return v, false, nil
+	case reflect.Slice, reflect.Array:
+		array_changed := false
+		if v.Kind() == reflect.Array {
+			copied := reflect.New(v.Type()).Elem()
+			copied.Set(v)
+			v = copied
+		}
+
+		deleted_indexes := make(map[int]bool)
+		for i := 0; i < v.Len(); i++ {
+			if w.stopped {
+				break
+			}
+			child_path := path.Item(i)
+
+			result, changed, deleted, err := w.visit(child_path, v.Index(i))
+			if err != nil {
+				return v, false, err
+			}
+			if deleted {
+				if v.Kind() == reflect.Array {
+					return v, false, fmt.Errorf(
+						"%s: can not delete an element of a fixed size array", child_path,
+					)
+				}
+				deleted_indexes[i] = true
+			} else if changed {
+				value, err := assignableValue(result, v.Type().Elem(), child_path)
+				if err != nil {
+					return v, false, err
+				}
+				v.Index(i).Set(value)
+				array_changed = v.Kind() == reflect.Array
+			}
+		}
+
+		if len(deleted_indexes) > 0 {
+			slices := reflect.MakeSlice(v.Type(), 0, v.Len()-len(deleted_indexes))
+			for i := 0; i < v.Len(); i++ {
+				if !deleted_indexes[i] {
+					slices = reflect.Append(slices, v.Index(i))
+				}
+			}
+			return slices, true, nil
+		}
+		return v, array_changed, nil
+	}
+	return v, false, nil
+}
+
+// assignableValue converts a value to be stored in a container with elements of type t.
+func assignableValue(v reflect.Value, t reflect.Type, path Path) (reflect.Value, error) {
+	if !v.IsValid() {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			return reflect.Zero(t), nil
+		}
+		return v, fmt.Errorf(
+			"%s: can not assign nil to %s", path, t,
+		)
+	}
+	if !v.Type().AssignableTo(t) {
+		return v, fmt.Errorf(
+			"%s: can not assign %s to %s", path, v.Type(), t,
+		)
+	}
+	return v, nil
+}
@@ -0,0 +1,174 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalkOrder(t *testing.T) {
+	visited := make([]string, 0)
+	err := Walk(setupDocument(), func(path Path, value interface{}, depth int) WalkAction {
+		visited = append(visited, fmt.Sprintf("%s:%d", path, depth))
+		return WalkContinue
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"one:1",
+		"one.four:2",
+		"one.four.five:3",
+		"one.four.five[0]:4",
+		"one.four.five[1]:4",
+		"one.four.five[2]:4",
+		"one.two:2",
+		"one.two.three:3",
+		"one.two.three[0]:4",
+		"one.two.three[1]:4",
+		"one.two.three[2]:4",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", visited, expected)
+	}
+}
+
+func TestWalkSkipStop(t *testing.T) {
+	visited := make([]string, 0)
+	Walk(setupDocument(), func(path Path, value interface{}, depth int) WalkAction {
+		visited = append(visited, path.String())
+		if path.String() == "one.four" {
+			return WalkSkip
+		}
+		if path.String() == "one.two.three[0]" {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+
+	expected := []string{
+		"one",
+		"one.four",
+		"one.two",
+		"one.two.three",
+		"one.two.three[0]",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", visited, expected)
+	}
+}
+
+func TestWalkReplaceDelete(t *testing.T) {
+	document := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "JOHN", "password": "secret"},
+			map[string]interface{}{"name": "JANE", "deleted": true},
+			map[string]interface{}{"name": "JACK"},
+		},
+		"scores":  []int{1, 2, 3, 4},
+		"headers": map[string][]string{"a": {"x", "y"}},
+		"old":     "value",
+	}
+
+	err := Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+		if m, ok := value.(map[string]interface{}); ok && m["deleted"] == true {
+			return WalkDelete
+		}
+		switch last := path[len(path)-1]; {
+		case last.Key == "password", last.Key == "old":
+			return WalkDelete
+		case last.Key == "name":
+			return WalkReplace(strings.ToLower(value.(string)))
+		case last.Key == "new":
+			return WalkReplace("visited")
+		}
+		if path.String() == "headers.a[0]" {
+			return WalkDelete
+		}
+		if n, ok := value.(int); ok {
+			if n%2 == 0 {
+				return WalkDelete
+			}
+			return WalkReplace(n * 10)
+		}
+		return WalkContinue
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "john"},
+			map[string]interface{}{"name": "jack"},
+		},
+		"scores":  []int{10, 30},
+		"headers": map[string][]string{"a": {"y"}},
+	}
+	if !reflect.DeepEqual(document, expected) {
+		t.Errorf("Results should equal \n\t%v \n \n\t%v", document, expected)
+	}
+}
+
+func TestWalkReplaceVisitsReplacement(t *testing.T) {
+	document := map[string]interface{}{
+		"a": 1,
+	}
+	err := Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+		if path.String() == "a" {
+			return WalkReplace(map[string]interface{}{"b": 2})
+		}
+		if path.String() == "a.b" {
+			return WalkReplace(3)
+		}
+		return WalkContinue
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(document, map[string]interface{}{"a": map[string]interface{}{"b": 3}}) {
+		t.Error("Replacement children should be visited. Got ", document)
+	}
+}
+
+func TestWalkTypeErrors(t *testing.T) {
+	document := map[string]interface{}{
+		"scores": []int{1},
+		"fixed":  [2]int{1, 2},
+	}
+
+	err := Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+		if path.String() == "scores[0]" {
+			return WalkReplace("one")
+		}
+		return WalkContinue
+	})
+	if !reflect.DeepEqual(err, fmt.Errorf("scores[0]: can not assign string to int")) {
+		t.Error("Should fail on incompatible type. Got ", err)
+	}
+
+	err = Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+		if path.String() == "fixed[0]" {
+			return WalkDelete
+		}
+		if path.String() == "fixed[1]" {
+			return WalkReplace(20)
+		}
+		return WalkContinue
+	})
+	if !reflect.DeepEqual(err, fmt.Errorf("fixed[0]: can not delete an element of a fixed size array")) {
+		t.Error("Should fail on array deletion. Got ", err)
+	}
+
+	err = Walk(document, func(path Path, value interface{}, depth int) WalkAction {
+		if path.String() == "fixed[1]" {
+			return WalkReplace(20)
+		}
+		return WalkContinue
+	})
+	if err != nil || !reflect.DeepEqual(document["fixed"], [2]int{1, 20}) {
+		t.Errorf("Array element should be replaced. Got %v, %v", document["fixed"], err)
+	}
+}
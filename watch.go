@@ -0,0 +1,295 @@
+package gjm
+
+import (
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// WatchBufferSize is the size of channels returned by Document.Watch.
+const WatchBufferSize = 64
+
+// EventOp is the kind of change reported by an Event.
+type EventOp int
+
+const (
+	// EventCreate reports a property which did not exist before.
+	EventCreate EventOp = iota
+	// EventUpdate reports a property with a new value.
+	EventUpdate
+	// EventDelete reports a property which does not exist anymore.
+	EventDelete
+)
+
+func (op EventOp) String() string {
+	switch op {
+	case EventCreate:
+		return "create"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Event describes a change of a watched property.
+// Old and New are deep copies, Old is nil for EventCreate and New is nil for EventDelete.
+type Event struct {
+	Path string
+	Op   EventOp
+	Old  interface{}
+	New  interface{}
+}
+
+type watcher struct {
+	pattern *Pattern
+	events  chan Event
+}
+
+// Watch subscribes to changes of properties matching a pattern. A property is
+// changed when it is created, deleted or gets a value which is not Equal to the
+// previous one, including changes made to its children or to an ancestor replacing it.
+// Events are delivered after the change is applied, changes rolled back by
+// Document.Update are never reported.
+//
+// Delivery never blocks the document: when the channel buffer of WatchBufferSize
+// events is full new events are dropped and counted by Dropped. Cancel stops
+// the subscription and closes the channel. An invalid pattern never matches,
+// the channel is closed immediately.
+//
+//	events, cancel := doc.Watch("features.*.enabled")
+//	defer cancel()
+//	for event := range events {
+//		log.Println(event.Path, event.Op, event.Old, event.New)
+//	}
+func (d *Document) Watch(pattern string) (<-chan Event, func()) {
+	events := make(chan Event, WatchBufferSize)
+
+	compiled, err := CompilePattern(pattern)
+	if err != nil {
+		close(events)
+		return events, func() {}
+	}
+
+	w := &watcher{pattern: compiled, events: events}
+
+	d.mu.Lock()
+	d.watchers = append(d.watchers, w)
+	d.mu.Unlock()
+
+	cancel := func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		for i, registered := range d.watchers {
+			if registered == w {
+				d.watchers = append(d.watchers[:i:i], d.watchers[i+1:]...)
+				close(w.events)
+				return
+			}
+		}
+	}
+	return events, cancel
+}
+
+// Dropped returns the number of events dropped because of full channels.
+func (d *Document) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// deliver sends events to watchers without blocking. Must be called holding the write lock.
+func (d *Document) deliver(events []watchEvent) {
+	for _, event := range events {
+		select {
+		case event.watcher.events <- event.event:
+		default:
+			atomic.AddUint64(&d.dropped, 1)
+		}
+	}
+}
+
+type watchEvent struct {
+	watcher *watcher
+	event   Event
+}
+
+// observe runs a change of path and returns events for watchers of the changed properties.
+// Must be called holding the write lock.
+func (d *Document) observe(path string, separator string, change func() error) ([]watchEvent, error) {
+	if len(d.watchers) == 0 {
+		return nil, change()
+	}
+	return d.observeRegion(changedRegion(path, separator), change)
+}
+
+// observeRegion runs a change which replaces at most the value at region.
+// Must be called holding the write lock.
+func (d *Document) observeRegion(region Path, change func() error) ([]watchEvent, error) {
+	if len(d.watchers) == 0 {
+		return nil, change()
+	}
+
+	// ancestors of the region replaced by the change, indexed by path length
+	ancestors := make([]*matchedValue, len(region))
+	ancestors_exist := make([]bool, len(region))
+	relevant := make([]*watcher, 0)
+	for _, w := range d.watchers {
+		matched := w.pattern.matchPrefix(region)
+		for i := 1; i < len(region); i++ {
+			if !w.pattern.Match(region[:i]) {
+				continue
+			}
+			matched = true
+			if ancestors[i] == nil {
+				value, exists := getPath(d.data, region[:i])
+				ancestors[i] = &matchedValue{region[:i], cloneValue(value)}
+				ancestors_exist[i] = exists
+			}
+		}
+		if matched {
+			relevant = append(relevant, w)
+		}
+	}
+	if len(relevant) == 0 {
+		return nil, change()
+	}
+
+	old_value, old_exists := getPath(d.data, region)
+	old_value = cloneValue(old_value)
+
+	if err := change(); err != nil {
+		return nil, err
+	}
+
+	new_value, new_exists := getPath(d.data, region)
+
+	events := make([]watchEvent, 0)
+	for _, w := range relevant {
+		for i, a := range ancestors {
+			if a == nil || !w.pattern.Match(a.path) {
+				continue
+			}
+			value, exists := getPath(d.data, a.path)
+			if event, changed := newEvent(a.path, a.value, ancestors_exist[i], value, exists); changed {
+				events = append(events, watchEvent{w, event})
+			}
+		}
+
+		old_matches := make(map[string]matchedValue)
+		order := make([]string, 0)
+		if old_exists {
+			for _, m := range collectMatches(w.pattern, region, old_value) {
+				old_matches[m.path.String()] = m
+				order = append(order, m.path.String())
+			}
+		}
+		new_matches := make(map[string]matchedValue)
+		if new_exists {
+			for _, m := range collectMatches(w.pattern, region, new_value) {
+				key := m.path.String()
+				if _, ok := old_matches[key]; !ok {
+					order = append(order, key)
+				}
+				new_matches[key] = m
+			}
+		}
+
+		for _, key := range order {
+			old_match, old_ok := old_matches[key]
+			new_match, new_ok := new_matches[key]
+			path := old_match.path
+			if !old_ok {
+				path = new_match.path
+			}
+			if event, changed := newEvent(path, old_match.value, old_ok, new_match.value, new_ok); changed {
+				events = append(events, watchEvent{w, event})
+			}
+		}
+	}
+	return events, nil
+}
+
+func newEvent(path Path, old_value interface{}, old_exists bool, new_value interface{}, new_exists bool) (Event, bool) {
+	event := Event{Path: path.String()}
+	switch {
+	case old_exists && new_exists:
+		if Equal(old_value, new_value) {
+			return event, false
+		}
+		event.Op = EventUpdate
+		event.Old = old_value
+		event.New = cloneValue(new_value)
+	case old_exists:
+		event.Op = EventDelete
+		event.Old = old_value
+	case new_exists:
+		event.Op = EventCreate
+		event.New = cloneValue(new_value)
+	default:
+		return event, false
+	}
+	return event, true
+}
+
+type matchedValue struct {
+	path  Path
+	value interface{}
+}
+
+// collectMatches returns values at and below path matching a pattern.
+func collectMatches(pattern *Pattern, path Path, value interface{}) []matchedValue {
+	matches := make([]matchedValue, 0)
+
+	var collect func(path Path, value interface{})
+	collect = func(path Path, value interface{}) {
+		if pattern.Match(path) {
+			matches = append(matches, matchedValue{path, value})
+		}
+		if !pattern.matchPrefix(path) {
+			return
+		}
+
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Map:
+			if !isStringMap(v) {
+				return
+			}
+			keys := make([]string, 0, v.Len())
+			for _, key := range v.MapKeys() {
+				keys = append(keys, key.String())
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				collect(path.Child(key), v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).Interface())
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				collect(path.Item(i), v.Index(i).Interface())
+			}
+		}
+	}
+	collect(path, value)
+
+	return matches
+}
+
+// changedRegion returns the path of the subtree a change of path may modify:
+// the path itself or, when it goes through an array, the path of the array.
+func changedRegion(path string, separator string) Path {
+	region := Path{}
+	segments, err := splitPath(path, separator)
+	if err != nil {
+		return region
+	}
+	for _, segment := range segments {
+		if len(segment.key) > 0 {
+			region = append(region, PathElement{Key: segment.key})
+		}
+		if len(segment.brackets) > 0 {
+			break
+		}
+	}
+	return region
+}
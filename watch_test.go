@@ -0,0 +1,146 @@
+package gjm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func setupFeatures() map[string]interface{} {
+	return map[string]interface{}{
+		"features": map[string]interface{}{
+			"search": map[string]interface{}{"enabled": false},
+			"chat":   map[string]interface{}{"enabled": true},
+		},
+		"version": 1,
+	}
+}
+
+func receive(events <-chan Event) []Event {
+	received := make([]Event, 0)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	doc := NewDocument(setupFeatures())
+	events, cancel := doc.Watch("features.*.enabled")
+	defer cancel()
+
+	doc.UpdateProperty("features.search.enabled", true)
+	doc.UpdateProperty("features.search.enabled", true)
+	doc.UpdateProperty("version", 2)
+	doc.CreateProperty("features.video.enabled", false)
+	doc.DeleteProperty("features.chat")
+	doc.UpdateProperty("features", map[string]interface{}{
+		"search": map[string]interface{}{"enabled": false},
+		"video":  map[string]interface{}{"enabled": false},
+	})
+
+	expected := []Event{
+		{Path: "features.search.enabled", Op: EventUpdate, Old: false, New: true},
+		{Path: "features.video.enabled", Op: EventCreate, New: false},
+		{Path: "features.chat.enabled", Op: EventDelete, Old: true},
+		{Path: "features.search.enabled", Op: EventUpdate, Old: true, New: false},
+	}
+	if received := receive(events); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Events should equal \n\t%v \n \n\t%v", received, expected)
+	}
+}
+
+func TestWatchAncestorsAndDescendants(t *testing.T) {
+	doc := NewDocument(setupFeatures())
+	features, cancel_features := doc.Watch("features")
+	defer cancel_features()
+	all, cancel_all := doc.Watch("features.**")
+	defer cancel_all()
+
+	doc.UpdateProperty("features.chat.enabled", false)
+
+	received := receive(features)
+	if len(received) != 1 || received[0].Path != "features" || received[0].Op != EventUpdate {
+		t.Error("Ancestor change should be reported. Got ", received)
+	}
+	if chat, _ := GetProperty(received[0].Old.(map[string]interface{}), "chat.enabled"); chat != true {
+		t.Error("Old value should be a copy made before the change. Got ", received[0].Old)
+	}
+
+	paths := make([]string, 0)
+	for _, event := range receive(all) {
+		paths = append(paths, event.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"features", "features.chat", "features.chat.enabled"}) {
+		t.Error("Every changed level should be reported. Got ", paths)
+	}
+}
+
+func TestWatchArrays(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+		},
+	})
+	events, cancel := doc.Watch("users[*].name")
+	defer cancel()
+
+	doc.DeleteProperty("users[0]")
+
+	expected := []Event{
+		{Path: "users[0].name", Op: EventUpdate, Old: "a", New: "b"},
+		{Path: "users[1].name", Op: EventDelete, Old: "b"},
+	}
+	if received := receive(events); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Events should equal \n\t%v \n \n\t%v", received, expected)
+	}
+}
+
+func TestWatchRollback(t *testing.T) {
+	doc := NewDocument(setupFeatures())
+	events, cancel := doc.Watch("**")
+	defer cancel()
+
+	doc.Update(func(tx *Tx) error {
+		tx.UpdateProperty("version", 2)
+		return fmt.Errorf("failed")
+	})
+	if received := receive(events); len(received) != 0 {
+		t.Error("Rolled back changes should not be reported. Got ", received)
+	}
+}
+
+func TestWatchCancelAndDrop(t *testing.T) {
+	doc := NewDocument(setupFeatures())
+	events, cancel := doc.Watch("version")
+
+	for i := 0; i < WatchBufferSize+10; i++ {
+		doc.UpdateProperty("version", i+2)
+	}
+	if doc.Dropped() != 10 {
+		t.Errorf("10 events should be dropped. Dropped %d", doc.Dropped())
+	}
+
+	cancel()
+	cancel()
+	if received := receive(events); len(received) != WatchBufferSize {
+		t.Errorf("Buffered events should be kept. Got %d", len(received))
+	}
+	if _, ok := <-events; ok {
+		t.Error("Channel should be closed")
+	}
+	doc.UpdateProperty("version", 0)
+
+	invalid, _ := doc.Watch("a[x]")
+	if _, ok := <-invalid; ok {
+		t.Error("Invalid pattern channel should be closed")
+	}
+}
@@ -0,0 +1,505 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// YAMLDocument is a YAML document which keeps its comments, key order and
+// formatting. Edit Data with the CRUD functions, then Encode writes the
+// document back: unchanged values are written as they were, changed values
+// are written in block style and new keys are appended to their mapping.
+//
+//	document, err := LoadYAML(config)
+//	err = UpdateProperty(document.Data, "server.port", 8080)
+//	config, err = document.Encode()
+type YAMLDocument struct {
+	Data map[string]interface{}
+
+	root    *yamlNode
+	prelude []string
+	foot    []string
+	crlf    bool
+}
+
+// ParseYAML decodes a YAML 1.2 document into `map[string]interface{}` and
+// `[]interface{}` values. Plain scalars are resolved with the core schema:
+// integers are `int`, floats `float64`, and integers which do not fit
+// in an `int` are `json.Number`. Block and flow collections, quoted and
+// block scalars, anchors, aliases and core tags are supported; explicit
+// keys `? ` and streams with several documents are not. Aliases can expand
+// to at most 100 nodes per byte of data.
+//
+//	config, err := ParseYAML(data)
+//	port, err := GetInt64(config, "server.port")
+func ParseYAML(data []byte) (map[string]interface{}, error) {
+	document, err := LoadYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return document.Data, nil
+}
+
+// LoadYAML parses a YAML document keeping everything Encode needs to
+// write it back the way it was written.
+func LoadYAML(data []byte) (*YAMLDocument, error) {
+	document, err := parseYAMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if document.root == nil {
+		document.root = &yamlNode{kind: yamlMapping, value: make(map[string]interface{})}
+	}
+	values, ok := document.root.value.(map[string]interface{})
+	if !ok || document.root.kind == yamlAlias {
+		return nil, fmt.Errorf("YAML document is not a mapping")
+	}
+	document.Data = Clone(values)
+	return document, nil
+}
+
+// Encode writes the document with the changes made to Data.
+func (d *YAMLDocument) Encode() ([]byte, error) {
+	e := &yamlEmitter{intact: make(map[string]bool)}
+	e.lines = append(e.lines, d.prelude...)
+	switch {
+	case d.root.kind != yamlMapping && Equal(d.root.value, d.Data):
+		// a flow mapping written as it was
+		e.lines = append(e.lines, d.root.raw_lines...)
+	case d.root.kind != yamlMapping || len(d.Data) == 0:
+		inline, lines, err := yamlLines(d.Data, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(inline) > 0 {
+			lines = append([]string{inline}, lines...)
+		}
+		e.lines = append(e.lines, lines...)
+	default:
+		if err := e.members(d.root, d.Data); err != nil {
+			return nil, err
+		}
+	}
+	e.lines = append(e.lines, d.foot...)
+
+	newline := "\n"
+	if d.crlf {
+		newline = "\r\n"
+	}
+	return []byte(strings.Join(e.lines, newline) + newline), nil
+}
+
+// MarshalYAML encodes a value as YAML in block style. Keys of maps are
+// sorted, OrderedMap keys are written in their order. Strings are quoted
+// when they would be read back as another type, multi-line strings are
+// written as literal block scalars.
+//
+//	data, err := MarshalYAML(config)
+func MarshalYAML(document interface{}) ([]byte, error) {
+	inline, lines, err := yamlLines(document, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(inline) > 0 {
+		lines = append([]string{inline}, lines...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// yamlEmitter writes a parsed document with the changes made to its values.
+type yamlEmitter struct {
+	lines []string
+	// anchors written with the value they had when the document was parsed
+	intact map[string]bool
+}
+
+// members writes the entries of a mapping which are still in value,
+// then the keys of value the mapping did not have.
+func (e *yamlEmitter) members(node *yamlNode, value interface{}) error {
+	object, _ := asObject(value)
+	written := make(map[string]bool, len(node.entries))
+	for _, entry := range node.entries {
+		v, ok := object.get(entry.key)
+		if !ok {
+			continue
+		}
+		written[entry.key] = true
+		e.lines = append(e.lines, entry.head...)
+		if err := e.entry(node.column, entry, v, false); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range yamlKeys(value) {
+		if written[key] {
+			continue
+		}
+		v, _ := object.get(key)
+		lines, err := yamlMember(strings.Repeat(" ", node.column)+yamlKey(key)+":", v, node.column)
+		if err != nil {
+			return err
+		}
+		e.lines = append(e.lines, lines...)
+	}
+	return nil
+}
+
+// items writes the entries of a sequence which are still in values, then
+// the items it did not have. Entries removed from values are not written.
+func (e *yamlEmitter) items(node *yamlNode, values []interface{}) error {
+	entries := matchYAMLEntries(node.entries, values)
+	for i, value := range values {
+		if entry := entries[i]; entry != nil {
+			e.lines = append(e.lines, entry.head...)
+			if err := e.entry(node.column, entry, value, true); err != nil {
+				return err
+			}
+			continue
+		}
+		lines, err := yamlItem(node.column, value)
+		if err != nil {
+			return err
+		}
+		e.lines = append(e.lines, lines...)
+	}
+	return nil
+}
+
+// matchYAMLEntries returns the entry of a sequence each value was parsed
+// from, nil for new values. Entries and values are matched by the longest
+// common subsequence of equal values, the entries left between two matches
+// are the ones of the changed values between them, in order.
+func matchYAMLEntries(entries []*yamlEntry, values []interface{}) []*yamlEntry {
+	n, m := len(entries), len(values)
+	equal := make([][]bool, n)
+	// lengths[i][j] is the length of the subsequence of entries[i:] and values[j:]
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		equal[i] = make([]bool, m)
+		for j := m - 1; j >= 0; j-- {
+			equal[i][j] = Equal(entries[i].value.value, values[j])
+			switch {
+			case equal[i][j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	matched := make([]*yamlEntry, m)
+	gap_i, gap_j := 0, 0
+	pair := func(end_i, end_j int) {
+		for ; gap_i < end_i && gap_j < end_j; gap_i, gap_j = gap_i+1, gap_j+1 {
+			matched[gap_j] = entries[gap_i]
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal[i][j]:
+			pair(i, j)
+			matched[j] = entries[i]
+			i, j = i+1, j+1
+			gap_i, gap_j = i, j
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	pair(n, m)
+	return matched
+}
+
+// entry writes a mapping entry or a sequence entry of a parsed document.
+func (e *yamlEmitter) entry(column int, entry *yamlEntry, value interface{}, item bool) error {
+	pad := strings.Repeat(" ", column)
+	node := entry.value
+	same := Equal(node.value, value)
+	if node.kind == yamlAlias {
+		same = same && e.intact[node.alias]
+	}
+	if len(node.anchor) > 0 {
+		e.intact[node.anchor] = same
+	}
+
+	if (node.kind == yamlScalar || node.kind == yamlAlias) && same {
+		e.lines = append(e.lines, pad+entry.prefix+node.raw+entry.suffix)
+		e.lines = append(e.lines, node.raw_lines...)
+		return nil
+	}
+
+	nested := &yamlEmitter{intact: e.intact}
+	var err error
+	switch values, is_slice := value.([]interface{}); {
+	case node.kind == yamlMapping && yamlObjectLen(value) > 0:
+		err = nested.members(node, value)
+	case node.kind == yamlSequence && is_slice && len(values) > 0:
+		err = nested.items(node, values)
+	default:
+		// The value is written again
+		var lines []string
+		if item {
+			lines, err = yamlItem(column, value)
+		} else {
+			lines, err = yamlMember(pad+strings.TrimRight(entry.prefix, " \t"), value, column)
+		}
+		if err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			lines[0] += entry.suffix
+		}
+		e.lines = append(e.lines, lines...)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	first := ""
+	if len(nested.lines) > 0 {
+		first = strings.TrimSpace(nested.lines[0])
+	}
+	if node.compact && len(first) > 0 && first[0] != '#' {
+		// `- key: value` with the rest of the mapping below
+		nested.lines[0] = pad + entry.prefix + nested.lines[0][node.column:]
+		e.lines = append(e.lines, nested.lines...)
+		return nil
+	}
+	header := pad + strings.TrimRight(entry.prefix, " \t")
+	if len(node.props) > 0 {
+		header = pad + entry.prefix + node.props
+	}
+	e.lines = append(e.lines, header+entry.suffix)
+	e.lines = append(e.lines, nested.lines...)
+	return nil
+}
+
+// yamlKeys returns the keys of an object in the order they are written.
+func yamlKeys(value interface{}) []string {
+	if ordered, ok := value.(*OrderedMap); ok {
+		return ordered.Keys()
+	}
+	keys := make([]string, 0)
+	if values, ok := value.(map[string]interface{}); ok {
+		for key := range values {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// yamlObjectLen returns the number of keys of an object, -1 for other values.
+func yamlObjectLen(value interface{}) int {
+	if object, ok := asObject(value); ok {
+		return object.len()
+	}
+	return -1
+}
+
+// yamlMember returns the lines of a mapping entry. The key is written in prefix.
+func yamlMember(prefix string, value interface{}, column int) ([]string, error) {
+	inline, lines, err := yamlLines(value, column+2)
+	if err != nil {
+		return nil, err
+	}
+	if len(inline) > 0 {
+		prefix += " " + inline
+	}
+	return append([]string{prefix}, lines...), nil
+}
+
+// yamlItem returns the lines of a sequence entry. Collections start on the
+// line of the entry: `- key: value`.
+func yamlItem(column int, value interface{}) ([]string, error) {
+	inline, lines, err := yamlLines(value, column+2)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.Repeat(" ", column) + "-"
+	if len(inline) > 0 {
+		return append([]string{prefix + " " + inline}, lines...), nil
+	}
+	lines[0] = prefix + " " + lines[0][column+2:]
+	return lines, nil
+}
+
+// yamlLines returns how a value is written: scalars and empty collections
+// inline, block collections as lines indented by column. Block scalars
+// have an inline header and lines.
+func yamlLines(value interface{}, column int) (string, []string, error) {
+	pad := strings.Repeat(" ", column)
+	switch v := value.(type) {
+	case nil:
+		return "null", nil, nil
+	case string:
+		inline, lines := yamlString(v, column)
+		return inline, lines, nil
+	case bool:
+		return strconv.FormatBool(v), nil, nil
+	case json.Number:
+		if _, ok := resolveYAMLScalar(string(v)).(string); ok {
+			return "", nil, fmt.Errorf("%s is not a valid number", v)
+		}
+		return string(v), nil, nil
+	case *OrderedMap, map[string]interface{}:
+		keys := yamlKeys(v)
+		if len(keys) == 0 {
+			return "{}", nil, nil
+		}
+		object, _ := asObject(v)
+		lines := make([]string, 0, len(keys))
+		for _, key := range keys {
+			item, _ := object.get(key)
+			member, err := yamlMember(pad+yamlKey(key)+":", item, column)
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, member...)
+		}
+		return "", lines, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]", nil, nil
+		}
+		lines := make([]string, 0, len(v))
+		for _, item := range v {
+			entry, err := yamlItem(column, item)
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, entry...)
+		}
+		return "", lines, nil
+	}
+
+	if ordered, ok := value.(*OrderedMap); ok && ordered == nil {
+		return "null", nil, nil
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return ".nan", nil, nil
+		case math.IsInf(f, 1):
+			return ".inf", nil, nil
+		case math.IsInf(f, -1):
+			return "-.inf", nil, nil
+		}
+		// 1.0 is written 1.0, 1 would be read back as an int
+		text := formatDouble(f)
+		if yamlIntRe.MatchString(text) {
+			text += ".0"
+		}
+		return text, nil, nil
+	}
+
+	// Other types are written the way encoding/json encodes them
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	decoded, err := ParseJSON(append(append([]byte(`{"value":`), encoded...), '}'))
+	if err != nil {
+		return "", nil, err
+	}
+	return yamlLines(decoded["value"], column)
+}
+
+// yamlKey returns how a key is written.
+func yamlKey(key string) string {
+	if yamlNeedsQuotes(key) {
+		encoded, _ := encodeValue(key)
+		return string(encoded)
+	}
+	return key
+}
+
+// yamlString returns how a string is written: plain when it is read back
+// as the same string, as a literal block scalar when it has several lines,
+// double quoted otherwise.
+func yamlString(s string, column int) (string, []string) {
+	if _, ok := resolveYAMLScalar(s).(string); ok && !yamlNeedsQuotes(s) {
+		return s, nil
+	}
+
+	body := strings.TrimRight(s, "\n")
+	trailing := len(s) - len(body)
+	literal := strings.Contains(body, "\n") && !strings.HasPrefix(body, " ") && !strings.HasPrefix(body, "\t")
+	lines := strings.Split(body, "\n")
+	for _, line := range lines {
+		for _, r := range line {
+			if r != '\t' && !unicode.IsPrint(r) {
+				literal = false
+			}
+		}
+		if len(line) > 0 && len(strings.TrimSpace(line)) == 0 {
+			literal = false
+		}
+	}
+	if !literal {
+		encoded, _ := encodeValue(s)
+		return string(encoded), nil
+	}
+
+	header := "|"
+	switch {
+	case trailing == 0:
+		header = "|-"
+	case trailing > 1:
+		header = "|+"
+	}
+	pad := strings.Repeat(" ", column)
+	for i, line := range lines {
+		if len(line) > 0 {
+			lines[i] = pad + line
+		}
+	}
+	for i := 1; i < trailing; i++ {
+		lines = append(lines, "")
+	}
+	return header, lines
+}
+
+// yamlNeedsQuotes reports whether a string can not be written as a plain scalar.
+func yamlNeedsQuotes(s string) bool {
+	if len(s) == 0 || strings.ContainsRune("?:,[]{}#&*!|>'\"%@`", rune(s[0])) {
+		return true
+	}
+	if s[0] == '-' && (len(s) == 1 || s[1] == ' ' || s[1] == '\t') {
+		return true
+	}
+	if s[0] == ' ' || s[0] == '\t' || s[len(s)-1] == ' ' || s[len(s)-1] == '\t' || s[len(s)-1] == ':' {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, ":\t") || strings.Contains(s, " #") || strings.Contains(s, "\t#") {
+		return true
+	}
+	if strings.HasPrefix(s, "---") || strings.HasPrefix(s, "...") {
+		return true
+	}
+	for _, r := range s {
+		if r != '\t' && !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
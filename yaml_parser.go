@@ -0,0 +1,1176 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	yamlIntRe   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlOctalRe = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHexRe   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloatRe = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+	yamlAlias
+)
+
+// yamlNode is a node of a YAML document as it was written.
+// Scalars, flow collections and aliases keep their source text,
+// block collections keep their entries.
+type yamlNode struct {
+	kind   yamlKind
+	column int
+	// compact collections start on the line of their sequence entry: `- key: value`
+	compact bool
+	flow    bool
+	plain   bool
+	props   string
+	anchor  string
+	alias   string
+	// source text on the line of the entry and the following lines
+	raw       string
+	raw_lines []string
+	text      string
+	entries   []*yamlEntry
+	value     interface{}
+}
+
+// yamlEntry is a mapping entry or a sequence entry with the comments above it.
+type yamlEntry struct {
+	head   []string
+	key    string
+	prefix string
+	suffix string
+	value  *yamlNode
+}
+
+type yamlParser struct {
+	lines   []string
+	line    int
+	pending []string
+	anchors map[string]*yamlNode
+	aliases *yamlAliasBudget
+}
+
+// yamlAliasNodesPerByte is how many nodes aliases can expand to per byte
+// of input. Without a limit nested aliases like in the "billion laughs"
+// document make a few hundred bytes decode to billions of values.
+const yamlAliasNodesPerByte = 100
+
+// yamlAliasBudget counts the nodes copied by aliases.
+type yamlAliasBudget struct {
+	remaining int
+}
+
+// expand returns a copy of the value of an anchored node.
+func (b *yamlAliasBudget) expand(target *yamlNode) (interface{}, error) {
+	b.remaining -= countYAMLNodes(target.value, b.remaining)
+	if b.remaining < 0 {
+		return nil, fmt.Errorf("aliases expand to too many nodes")
+	}
+	return cloneValue(target.value), nil
+}
+
+// countYAMLNodes returns the number of nodes of a value,
+// counting stops once it is over limit.
+func countYAMLNodes(value interface{}, limit int) int {
+	count := 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			if count > limit {
+				break
+			}
+			count += countYAMLNodes(item, limit-count)
+		}
+	case []interface{}:
+		for _, item := range v {
+			if count > limit {
+				break
+			}
+			count += countYAMLNodes(item, limit-count)
+		}
+	}
+	return count
+}
+
+func (p *yamlParser) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("Line %d: %s", line+1, fmt.Sprintf(format, args...))
+}
+
+// parseYAMLDocument parses a YAML stream holding a single document.
+func parseYAMLDocument(data []byte) (*YAMLDocument, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	p := &yamlParser{
+		lines:   lines,
+		anchors: make(map[string]*yamlNode),
+		aliases: &yamlAliasBudget{remaining: yamlAliasNodesPerByte * len(data)},
+	}
+	document := &YAMLDocument{crlf: len(text) < len(data)}
+
+	// Directives and the document start marker
+	for i, line := range p.lines {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if !strings.HasPrefix(line, "%") && !isYAMLMarker(line, "---") {
+			break
+		}
+		document.prelude = p.lines[:i+1]
+		if isYAMLMarker(line, "---") {
+			if rest := strings.TrimSpace(line[3:]); len(rest) > 0 && rest[0] != '#' {
+				return nil, p.errorf(i, "content after the document start marker is not supported")
+			}
+			break
+		}
+	}
+	p.line = len(document.prelude)
+
+	root, err := p.parseBlock(-1, false)
+	if err != nil {
+		return nil, err
+	}
+	document.root = root
+
+	p.skipComments()
+	document.foot = p.pending
+	ended := false
+	for ; p.line < len(p.lines); p.line++ {
+		line := p.lines[p.line]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !ended && isYAMLMarker(line, "..."):
+			ended = true
+		case len(trimmed) == 0 || trimmed[0] == '#':
+		case isYAMLMarker(line, "---"), ended:
+			return nil, p.errorf(p.line, "YAML streams with several documents are not supported")
+		default:
+			return nil, p.errorf(p.line, "unexpected indentation")
+		}
+		document.foot = append(document.foot, line)
+	}
+	return document, nil
+}
+
+// isYAMLMarker reports whether a line is a document marker like `---` or `...`.
+func isYAMLMarker(line string, marker string) bool {
+	return strings.HasPrefix(line, marker) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t')
+}
+
+// skipComments moves past blank lines and comments, keeping them for the next entry.
+func (p *yamlParser) skipComments() {
+	for ; p.line < len(p.lines); p.line++ {
+		trimmed := strings.TrimSpace(p.lines[p.line])
+		if len(trimmed) > 0 && trimmed[0] != '#' {
+			return
+		}
+		p.pending = append(p.pending, p.lines[p.line])
+	}
+}
+
+// atEnd reports whether there is no content line left in the document.
+func (p *yamlParser) atEnd() bool {
+	if p.line >= len(p.lines) {
+		return true
+	}
+	line := p.lines[p.line]
+	return isYAMLMarker(line, "---") || isYAMLMarker(line, "...")
+}
+
+// parseBlock parses the block node on the following lines when it is
+// indented more than parent. Sequences of mapping values may be indented
+// as much as their key. It returns nil when there is no such node.
+func (p *yamlParser) parseBlock(parent int, in_mapping bool) (*yamlNode, error) {
+	p.skipComments()
+	if p.atEnd() {
+		return nil, nil
+	}
+	line := p.lines[p.line]
+	column := indentOf(line)
+	if column < parent || (column == parent && !(in_mapping && isYAMLSequenceEntry(line[column:]))) {
+		return nil, nil
+	}
+	return p.parseNodeAt(column)
+}
+
+// parseNodeAt parses the node starting at a column of the current line.
+func (p *yamlParser) parseNodeAt(column int) (*yamlNode, error) {
+	line := p.lines[p.line]
+	if strings.HasPrefix(line[column:], "\t") {
+		return nil, p.errorf(p.line, "tabs are not allowed in indentation")
+	}
+	content, _ := splitYAMLComment(line[column:])
+	if content == "?" || strings.HasPrefix(content, "? ") {
+		return nil, p.errorf(p.line, "explicit keys are not supported")
+	}
+	if isYAMLSequenceEntry(content) {
+		return p.parseSequence(column)
+	}
+	if _, _, ok := parseYAMLKey(content); ok {
+		return p.parseMapping(column)
+	}
+
+	// A scalar on its own line is kept as it is written
+	start := p.line
+	node, err := p.parseInline(content, column-1)
+	if err != nil {
+		return nil, err
+	}
+	node.raw = ""
+	node.raw_lines = append([]string(nil), p.lines[start:p.line]...)
+	return node, nil
+}
+
+func (p *yamlParser) parseMapping(column int) (*yamlNode, error) {
+	node := &yamlNode{kind: yamlMapping, column: column}
+	values := make(map[string]interface{})
+	for {
+		p.skipComments()
+		if p.atEnd() {
+			break
+		}
+		line := p.lines[p.line]
+		indent := indentOf(line)
+		if indent < column {
+			break
+		}
+		if indent > column {
+			return nil, p.errorf(p.line, "unexpected indentation")
+		}
+		if strings.HasPrefix(line[column:], "\t") {
+			return nil, p.errorf(p.line, "tabs are not allowed in indentation")
+		}
+
+		content, suffix := splitYAMLComment(line[column:])
+		if isYAMLSequenceEntry(content) {
+			// a sequence of the parent mapping indented like its key
+			break
+		}
+		key, end, ok := parseYAMLKey(content)
+		if !ok {
+			return nil, p.errorf(p.line, "expected a mapping entry")
+		}
+		if _, exists := values[key]; exists {
+			return nil, p.errorf(p.line, "duplicate key %s", key)
+		}
+
+		entry := &yamlEntry{head: p.pending, key: key, suffix: suffix}
+		p.pending = nil
+		text := strings.TrimLeft(content[end:], " \t")
+		entry.prefix = content[:len(content)-len(text)]
+		if len(text) == 0 {
+			entry.prefix = content
+		}
+
+		value, err := p.parseValue(text, column, true)
+		if err != nil {
+			return nil, err
+		}
+		entry.value = value
+		node.entries = append(node.entries, entry)
+		values[key] = value.value
+	}
+	node.value = values
+	return node, nil
+}
+
+func (p *yamlParser) parseSequence(column int) (*yamlNode, error) {
+	node := &yamlNode{kind: yamlSequence, column: column}
+	items := make([]interface{}, 0)
+	for {
+		p.skipComments()
+		if p.atEnd() {
+			break
+		}
+		line := p.lines[p.line]
+		indent := indentOf(line)
+		if indent < column {
+			break
+		}
+		if indent > column {
+			return nil, p.errorf(p.line, "unexpected indentation")
+		}
+
+		content, suffix := splitYAMLComment(line[column:])
+		if !isYAMLSequenceEntry(content) {
+			break
+		}
+		entry := &yamlEntry{head: p.pending, suffix: suffix}
+		p.pending = nil
+		text := strings.TrimLeft(content[1:], " \t")
+		entry.prefix = content[:len(content)-len(text)]
+		if len(text) == 0 {
+			entry.prefix = content
+		}
+
+		var value *yamlNode
+		var err error
+		if _, _, ok := parseYAMLKey(text); ok || isYAMLSequenceEntry(text) {
+			// A compact collection: blank the entry indicator and parse the collection in place
+			start := column + len(entry.prefix)
+			p.lines[p.line] = line[:column] + strings.Repeat(" ", len(entry.prefix)) + line[start:]
+			entry.suffix = ""
+			if value, err = p.parseNodeAt(start); err != nil {
+				return nil, err
+			}
+			value.compact = true
+		} else if value, err = p.parseValue(text, column, false); err != nil {
+			return nil, err
+		}
+		entry.value = value
+		node.entries = append(node.entries, entry)
+		items = append(items, value.value)
+	}
+	node.value = items
+	return node, nil
+}
+
+// parseValue parses the value of an entry written after its key or
+// entry indicator at column, or on the following lines.
+func (p *yamlParser) parseValue(text string, column int, in_mapping bool) (*yamlNode, error) {
+	line := p.line
+	props, anchor, tag, rest, err := splitYAMLProps(text)
+	if err != nil {
+		return nil, p.errorf(line, "%v", err)
+	}
+
+	var node *yamlNode
+	if len(rest) == 0 {
+		p.line++
+		if node, err = p.parseBlock(column, in_mapping); err != nil {
+			return nil, err
+		}
+		if node == nil {
+			node = &yamlNode{kind: yamlScalar, plain: true, raw: text}
+		} else if node.kind != yamlScalar {
+			node.props = props
+		} else {
+			node.raw = props
+		}
+	} else {
+		if len(props) > 0 && rest[0] == '*' {
+			return nil, p.errorf(line, "an alias can not have properties")
+		}
+		if node, err = p.parseInline(rest, column); err != nil {
+			return nil, err
+		}
+		node.raw = text
+	}
+
+	if err := applyYAMLTag(node, tag); err != nil {
+		return nil, p.errorf(line, "%v", err)
+	}
+	if len(anchor) > 0 {
+		node.anchor = anchor
+		p.anchors[anchor] = node
+	}
+	return node, nil
+}
+
+// parseInline parses a scalar, a flow collection or an alias starting on
+// the current line. Following lines indented more than column continue it.
+func (p *yamlParser) parseInline(text string, column int) (*yamlNode, error) {
+	start := p.line
+	node := &yamlNode{kind: yamlScalar}
+
+	switch text[0] {
+	case '*':
+		target, ok := p.anchors[text[1:]]
+		if !ok {
+			return nil, p.errorf(start, "unknown alias %s", text)
+		}
+		node.kind = yamlAlias
+		node.alias = text[1:]
+		value, err := p.aliases.expand(target)
+		if err != nil {
+			return nil, p.errorf(start, "%v", err)
+		}
+		node.value = value
+		p.line++
+
+	case '|', '>':
+		p.line++
+		value, err := p.parseBlockScalar(text, column)
+		if err != nil {
+			return nil, p.errorf(start, "%v", err)
+		}
+		node.raw_lines = append([]string(nil), p.lines[start+1:p.line]...)
+		node.text = value
+		node.value = value
+
+	case '[', '{':
+		node.flow = true
+		source := text
+		for {
+			f := &yamlFlowParser{text: source, anchors: p.anchors, aliases: p.aliases}
+			remaining := p.aliases.remaining
+			value, err := f.parse()
+			if err == errYAMLFlowIncomplete && p.line+1 < len(p.lines) {
+				// the collection is parsed again with the next line
+				p.aliases.remaining = remaining
+				p.line++
+				node.raw_lines = append(node.raw_lines, p.lines[p.line])
+				content, _ := splitYAMLComment(p.lines[p.line])
+				source += "\n" + content
+				continue
+			}
+			if err != nil {
+				return nil, p.errorf(start, "%v", err)
+			}
+			node.value = value
+			break
+		}
+		p.line++
+
+	case '"', '\'':
+		source := text
+		end := closingYAMLQuote(source)
+		for end < 0 {
+			if p.line+1 >= len(p.lines) {
+				return nil, p.errorf(start, "unterminated quoted scalar")
+			}
+			p.line++
+			node.raw_lines = append(node.raw_lines, p.lines[p.line])
+			source += "\n" + p.lines[p.line]
+			end = closingYAMLQuote(source)
+		}
+		if after, _ := splitYAMLComment(source[end+1:]); len(strings.TrimSpace(after)) > 0 {
+			return nil, p.errorf(p.line, "unexpected %s after a quoted scalar", strings.TrimSpace(after))
+		}
+		value, err := unquoteYAML(source[:end+1])
+		if err != nil {
+			return nil, p.errorf(start, "%v", err)
+		}
+		node.text = value
+		node.value = value
+		p.line++
+
+	default:
+		source := text
+		p.line++
+		for p.line < len(p.lines) {
+			// empty lines are kept when the scalar continues after them
+			next := p.line
+			for next < len(p.lines) && len(strings.TrimSpace(p.lines[next])) == 0 {
+				next++
+			}
+			if next == len(p.lines) {
+				break
+			}
+			line := p.lines[next]
+			trimmed := strings.TrimSpace(line)
+			if trimmed[0] == '#' || indentOf(line) <= column || isYAMLMarker(line, "---") || isYAMLMarker(line, "...") {
+				break
+			}
+			content, _ := splitYAMLComment(line)
+			if _, _, ok := parseYAMLKey(strings.TrimSpace(content)); ok {
+				// a mapping entry can not continue a scalar
+				break
+			}
+			node.raw_lines = append(node.raw_lines, p.lines[p.line:next+1]...)
+			source += strings.Repeat("\n", next-p.line+1) + strings.TrimSpace(content)
+			p.line = next + 1
+		}
+		node.plain = true
+		node.text = foldYAMLLines(source)
+		node.value = resolveYAMLScalar(node.text)
+	}
+	return node, nil
+}
+
+// parseBlockScalar reads the lines of a literal `|` or folded `>` scalar.
+func (p *yamlParser) parseBlockScalar(header string, column int) (string, error) {
+	indicator, chomp := 0, byte(0)
+	for i := 1; i < len(header); i++ {
+		switch c := header[i]; {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+		case c >= '1' && c <= '9' && indicator == 0:
+			indicator = int(c - '0')
+		default:
+			return "", fmt.Errorf("invalid block scalar header %s", header)
+		}
+	}
+
+	start := p.line
+	block_indent := -1
+	if indicator > 0 {
+		block_indent = column + indicator
+	}
+	body := make([]string, 0)
+	last := -1
+	for p.line < len(p.lines) {
+		line := p.lines[p.line]
+		if len(strings.TrimSpace(line)) == 0 {
+			body = append(body, "")
+			p.line++
+			continue
+		}
+		indent := indentOf(line)
+		if block_indent < 0 {
+			if indent <= column {
+				break
+			}
+			block_indent = indent
+		}
+		if indent < block_indent || (indent == 0 && p.atEnd()) {
+			break
+		}
+		body = append(body, line[block_indent:])
+		last = len(body) - 1
+		p.line++
+	}
+	trailing := len(body) - last - 1
+	// Trailing blank lines stay with the following entry
+	p.line = start + last + 1
+	body = body[:last+1]
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	var value string
+	if header[0] == '|' {
+		value = strings.Join(body, "\n")
+	} else {
+		value = foldYAMLBlock(body)
+	}
+	switch chomp {
+	case '-':
+		return value, nil
+	case '+':
+		return value + "\n" + strings.Repeat("\n", trailing), nil
+	}
+	return value + "\n", nil
+}
+
+// foldYAMLBlock folds the lines of a folded block scalar: line breaks
+// between lines which are not more indented become spaces.
+func foldYAMLBlock(lines []string) string {
+	var builder strings.Builder
+	empty := 0
+	previous_normal := false
+	for i, line := range lines {
+		if len(line) == 0 {
+			empty++
+			continue
+		}
+		normal := line[0] != ' ' && line[0] != '\t'
+		switch {
+		case i == empty:
+			builder.WriteString(strings.Repeat("\n", empty))
+		case previous_normal && normal && empty == 0:
+			builder.WriteByte(' ')
+		case previous_normal && normal:
+			builder.WriteString(strings.Repeat("\n", empty))
+		default:
+			builder.WriteString(strings.Repeat("\n", empty+1))
+		}
+		builder.WriteString(line)
+		previous_normal = normal
+		empty = 0
+	}
+	return builder.String()
+}
+
+// foldYAMLLines folds the lines of a flow scalar: a line break becomes
+// a space, empty lines become line breaks.
+func foldYAMLLines(text string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	segments := strings.Split(text, "\n")
+	folded := strings.TrimRight(segments[0], " \t")
+	empty := 0
+	for i := 1; i < len(segments); i++ {
+		segment := strings.TrimLeft(segments[i], " \t")
+		if i < len(segments)-1 {
+			segment = strings.TrimRight(segment, " \t")
+			if len(segment) == 0 {
+				empty++
+				continue
+			}
+		}
+		if empty > 0 {
+			folded += strings.Repeat("\n", empty)
+		} else {
+			folded += " "
+		}
+		folded += segment
+		empty = 0
+	}
+	return folded
+}
+
+// foldYAMLEscapedLines folds the lines of a double quoted scalar. A line
+// ending with an escaped line break `\` is joined to the next one without
+// a space, the indentation of the next line is dropped.
+func foldYAMLEscapedLines(text string) string {
+	var builder strings.Builder
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' {
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == '\n' {
+			builder.WriteString(foldYAMLLines(text[start:i]))
+			start = i + 2
+			for start < len(text) && (text[start] == ' ' || text[start] == '\t') {
+				start++
+			}
+			i = start - 1
+			continue
+		}
+		// the escaped character
+		i++
+	}
+	builder.WriteString(foldYAMLLines(text[start:]))
+	return builder.String()
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isYAMLSequenceEntry(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ") || strings.HasPrefix(content, "-\t")
+}
+
+// parseYAMLKey reads the key of a mapping entry. It returns the key and
+// the position after its colon.
+func parseYAMLKey(content string) (string, int, bool) {
+	if len(content) == 0 {
+		return "", 0, false
+	}
+	if content[0] == '"' || content[0] == '\'' {
+		end := closingYAMLQuote(content)
+		if end < 0 {
+			return "", 0, false
+		}
+		after := strings.TrimLeft(content[end+1:], " \t")
+		if !strings.HasPrefix(after, ":") || (len(after) > 1 && after[1] != ' ' && after[1] != '\t') {
+			return "", 0, false
+		}
+		key, err := unquoteYAML(content[:end+1])
+		if err != nil {
+			return "", 0, false
+		}
+		return key, len(content) - len(after) + 1, true
+	}
+	if strings.ContainsRune("[]{},#&*!|>%@`", rune(content[0])) || strings.HasPrefix(content, "? ") {
+		return "", 0, false
+	}
+	for i := 0; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ' || content[i+1] == '\t') {
+			key := strings.TrimRight(content[:i], " \t")
+			return key, i + 1, len(key) > 0
+		}
+	}
+	return "", 0, false
+}
+
+// splitYAMLComment splits a line into its content and the trailing
+// whitespace and comment.
+func splitYAMLComment(line string) (string, string) {
+	in_single, in_double := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case in_double:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				in_double = false
+			}
+		case in_single:
+			if c == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					in_single = false
+				}
+			}
+		case (c == '"' || c == '\'') && startsYAMLScalar(line, i):
+			in_double, in_single = c == '"', c == '\''
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			content := strings.TrimRight(line[:i], " \t")
+			return content, line[len(content):]
+		}
+	}
+	content := strings.TrimRight(line, " \t")
+	return content, line[len(content):]
+}
+
+// startsYAMLScalar reports whether a scalar may start at position i of
+// a line, so a quote there opens a quoted scalar.
+func startsYAMLScalar(line string, i int) bool {
+	if i > 0 && strings.IndexByte(" \t[{,", line[i-1]) < 0 {
+		return false
+	}
+	before := strings.TrimRight(line[:i], " \t")
+	if len(before) == 0 || strings.IndexByte(":-,[{?", before[len(before)-1]) >= 0 {
+		return true
+	}
+	// properties like `&anchor` or `!!str` before the scalar
+	word := before[strings.LastIndexAny(before, " \t")+1:]
+	return strings.HasPrefix(word, "&") || strings.HasPrefix(word, "!")
+}
+
+// splitYAMLProps splits the anchor and the tag written before a value.
+func splitYAMLProps(text string) (props string, anchor string, tag string, rest string, err error) {
+	rest = text
+	for len(rest) > 0 && (rest[0] == '&' || rest[0] == '!') {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		if rest[0] == '&' {
+			if len(anchor) > 0 {
+				return "", "", "", "", fmt.Errorf("a node can not have two anchors")
+			}
+			anchor = rest[1:end]
+		} else {
+			if len(tag) > 0 {
+				return "", "", "", "", fmt.Errorf("a node can not have two tags")
+			}
+			tag = rest[:end]
+		}
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	props = strings.TrimRight(text[:len(text)-len(rest)], " \t")
+	return props, anchor, tag, rest, nil
+}
+
+// applyYAMLTag resolves a tagged node with the YAML 1.2 core schema tags.
+func applyYAMLTag(node *yamlNode, tag string) error {
+	if len(tag) == 0 {
+		return nil
+	}
+	scalar := node.kind == yamlScalar && !node.flow
+	switch tag {
+	case "!", "!!str":
+		if scalar {
+			node.value = node.text
+			return nil
+		}
+	case "!!null", "!!bool", "!!int", "!!float":
+		if !scalar {
+			break
+		}
+		value := resolveYAMLScalar(node.text)
+		valid := false
+		switch tag {
+		case "!!null":
+			valid = value == nil
+		case "!!bool":
+			_, valid = value.(bool)
+		case "!!int":
+			_, valid = value.(int)
+			_, big := value.(json.Number)
+			valid = valid || big
+		case "!!float":
+			if i, ok := value.(int); ok {
+				value = float64(i)
+			}
+			_, valid = value.(float64)
+		}
+		if valid {
+			node.value = value
+			return nil
+		}
+		return fmt.Errorf("%q is not a valid %s", node.text, tag)
+	case "!!map":
+		if _, ok := node.value.(map[string]interface{}); ok {
+			return nil
+		}
+	case "!!seq":
+		if _, ok := node.value.([]interface{}); ok {
+			return nil
+		}
+	default:
+		return fmt.Errorf("tag %s is not supported", tag)
+	}
+	return fmt.Errorf("tag %s does not match the value", tag)
+}
+
+// resolveYAMLScalar resolves a plain scalar with the YAML 1.2 core schema.
+// Integers which do not fit in an int are returned as json.Number.
+func resolveYAMLScalar(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	switch {
+	case yamlIntRe.MatchString(text):
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+		return json.Number(strings.TrimPrefix(text, "+"))
+	case yamlOctalRe.MatchString(text):
+		if i, err := strconv.ParseInt(text[2:], 8, 64); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+	case yamlHexRe.MatchString(text):
+		if i, err := strconv.ParseInt(text[2:], 16, 64); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+	case yamlFloatRe.MatchString(text):
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// closingYAMLQuote returns the position of the quote closing the quoted
+// scalar text starts with, -1 when it is not closed.
+func closingYAMLQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteYAML decodes a single or double quoted scalar.
+func unquoteYAML(text string) (string, error) {
+	if text[0] == '\'' {
+		return strings.ReplaceAll(foldYAMLLines(text[1:len(text)-1]), "''", "'"), nil
+	}
+	inner := foldYAMLEscapedLines(text[1 : len(text)-1])
+
+	var builder strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c != '\\' {
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(inner) {
+			return "", fmt.Errorf("invalid escape sequence at the end of %s", text)
+		}
+		switch inner[i] {
+		case '0':
+			builder.WriteByte(0)
+		case 'a':
+			builder.WriteByte('\a')
+		case 'b':
+			builder.WriteByte('\b')
+		case 't', '\t':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'v':
+			builder.WriteByte('\v')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'e':
+			builder.WriteByte(0x1b)
+		case ' ', '"', '/', '\\':
+			builder.WriteByte(inner[i])
+		case 'N':
+			builder.WriteRune('\u0085')
+		case '_':
+			builder.WriteRune('\u00a0')
+		case 'L':
+			builder.WriteRune('\u2028')
+		case 'P':
+			builder.WriteRune('\u2029')
+		case 'x', 'u', 'U':
+			size := 2
+			switch inner[i] {
+			case 'u':
+				size = 4
+			case 'U':
+				size = 8
+			}
+			if i+size >= len(inner) {
+				return "", fmt.Errorf("invalid escape sequence in %s", text)
+			}
+			code, err := strconv.ParseUint(inner[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence in %s", text)
+			}
+			i += size
+			r := rune(code)
+			// surrogate pairs like \ud83d\ude00
+			if utf16.IsSurrogate(r) && i+6 < len(inner) && strings.HasPrefix(inner[i+1:], "\\u") {
+				if low, err := strconv.ParseUint(inner[i+3:i+7], 16, 32); err == nil {
+					if decoded := utf16.DecodeRune(r, rune(low)); decoded != utf8.RuneError {
+						r = decoded
+						i += 6
+					}
+				}
+			}
+			builder.WriteRune(r)
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c in %s", inner[i], text)
+		}
+	}
+	return builder.String(), nil
+}
+
+var errYAMLFlowIncomplete = fmt.Errorf("unterminated flow collection")
+
+// yamlFlowParser parses flow collections like `[a, b]` and `{a: 1}`.
+type yamlFlowParser struct {
+	text    string
+	pos     int
+	anchors map[string]*yamlNode
+	aliases *yamlAliasBudget
+}
+
+func (f *yamlFlowParser) parse() (interface{}, error) {
+	value, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpace()
+	if f.pos < len(f.text) {
+		return nil, fmt.Errorf("unexpected %s after a flow collection", f.text[f.pos:])
+	}
+	return value, nil
+}
+
+func (f *yamlFlowParser) skipSpace() {
+	for f.pos < len(f.text) && strings.IndexByte(" \t\n", f.text[f.pos]) >= 0 {
+		f.pos++
+	}
+}
+
+func (f *yamlFlowParser) value() (interface{}, error) {
+	node, err := f.node()
+	if err != nil {
+		return nil, err
+	}
+	return node.value, nil
+}
+
+// node reads a value with its anchor and tag, or an alias.
+func (f *yamlFlowParser) node() (*yamlNode, error) {
+	f.skipSpace()
+	anchor, tag := "", ""
+	for f.pos < len(f.text) && (f.text[f.pos] == '&' || f.text[f.pos] == '!') {
+		word := f.word()
+		if word[0] == '&' {
+			if len(anchor) > 0 {
+				return nil, fmt.Errorf("a node can not have two anchors")
+			}
+			anchor = word[1:]
+		} else {
+			if len(tag) > 0 {
+				return nil, fmt.Errorf("a node can not have two tags")
+			}
+			tag = word
+		}
+		f.skipSpace()
+	}
+	if f.pos >= len(f.text) {
+		return nil, errYAMLFlowIncomplete
+	}
+
+	node := &yamlNode{kind: yamlScalar}
+	switch f.text[f.pos] {
+	case '*':
+		if len(anchor) > 0 || len(tag) > 0 {
+			return nil, fmt.Errorf("an alias can not have properties")
+		}
+		name := f.word()[1:]
+		target, ok := f.anchors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown alias *%s", name)
+		}
+		node.kind = yamlAlias
+		value, err := f.aliases.expand(target)
+		if err != nil {
+			return nil, err
+		}
+		node.value = value
+		return node, nil
+	case '[', '{':
+		value, err := f.collection()
+		if err != nil {
+			return nil, err
+		}
+		node.flow = true
+		node.value = value
+	case '"', '\'':
+		text, err := f.scalar()
+		if err != nil {
+			return nil, err
+		}
+		node.text = text
+		node.value = text
+	case ']', '}', ',', ':':
+		return nil, fmt.Errorf("unexpected %c in a flow collection", f.text[f.pos])
+	default:
+		text, err := f.scalar()
+		if err != nil {
+			return nil, err
+		}
+		node.plain = true
+		node.text = text
+		node.value = resolveYAMLScalar(text)
+	}
+
+	if err := applyYAMLTag(node, tag); err != nil {
+		return nil, err
+	}
+	if len(anchor) > 0 {
+		node.anchor = anchor
+		f.anchors[anchor] = node
+	}
+	return node, nil
+}
+
+// word reads the text up to the next space or flow indicator.
+func (f *yamlFlowParser) word() string {
+	start := f.pos
+	for f.pos < len(f.text) && strings.IndexByte(" \t\n,[]{}", f.text[f.pos]) < 0 {
+		f.pos++
+	}
+	return f.text[start:f.pos]
+}
+
+// collection reads a flow sequence or a flow mapping.
+func (f *yamlFlowParser) collection() (interface{}, error) {
+	switch f.text[f.pos] {
+	case '[':
+		f.pos++
+		items := make([]interface{}, 0)
+		for {
+			f.skipSpace()
+			if f.pos >= len(f.text) {
+				return nil, errYAMLFlowIncomplete
+			}
+			if f.text[f.pos] == ']' {
+				f.pos++
+				return items, nil
+			}
+			item, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.pos++
+		values := make(map[string]interface{})
+		for {
+			f.skipSpace()
+			if f.pos >= len(f.text) {
+				return nil, errYAMLFlowIncomplete
+			}
+			if f.text[f.pos] == '}' {
+				f.pos++
+				return values, nil
+			}
+			key, err := f.scalar()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := values[key]; exists {
+				return nil, fmt.Errorf("duplicate key %s", key)
+			}
+			f.skipSpace()
+			var value interface{}
+			if f.pos < len(f.text) && f.text[f.pos] == ':' {
+				f.pos++
+				if value, err = f.value(); err != nil {
+					return nil, err
+				}
+			}
+			values[key] = value
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, fmt.Errorf("unexpected %c in a flow collection", f.text[f.pos])
+}
+
+// separator moves past a comma or reads the closing bracket.
+func (f *yamlFlowParser) separator(closing byte) error {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return errYAMLFlowIncomplete
+	}
+	switch f.text[f.pos] {
+	case ',':
+		f.pos++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("unexpected %c in a flow collection", f.text[f.pos])
+}
+
+// scalar reads a quoted or a plain scalar and returns its text.
+func (f *yamlFlowParser) scalar() (string, error) {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return "", errYAMLFlowIncomplete
+	}
+	if c := f.text[f.pos]; c == '"' || c == '\'' {
+		end := closingYAMLQuote(f.text[f.pos:])
+		if end < 0 {
+			return "", errYAMLFlowIncomplete
+		}
+		text := f.text[f.pos : f.pos+end+1]
+		f.pos += end + 1
+		return unquoteYAML(text)
+	}
+
+	start := f.pos
+	for ; f.pos < len(f.text); f.pos++ {
+		c := f.text[f.pos]
+		if strings.IndexByte(",[]{}", c) >= 0 {
+			break
+		}
+		if c == ':' && (f.pos+1 == len(f.text) || strings.IndexByte(" \t\n,[]{}", f.text[f.pos+1]) >= 0) {
+			break
+		}
+	}
+	return strings.Join(strings.Fields(f.text[start:f.pos]), " "), nil
+}
@@ -0,0 +1,200 @@
+package gjm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLScalars(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{name: "int", input: "v: 42", expected: 42},
+		{name: "negative int", input: "v: -17", expected: -17},
+		{name: "octal", input: "v: 0o17", expected: 15},
+		{name: "hex", input: "v: 0x1F", expected: 31},
+		{name: "big int", input: "v: 123456789012345678901234567890", expected: json.Number("123456789012345678901234567890")},
+		{name: "float", input: "v: 1.5", expected: 1.5},
+		{name: "exponent", input: "v: 1e3", expected: 1000.0},
+		{name: "infinity", input: "v: -.inf", expected: math.Inf(-1)},
+		{name: "true", input: "v: true", expected: true},
+		{name: "False", input: "v: False", expected: false},
+		{name: "yes is a string", input: "v: yes", expected: "yes"},
+		{name: "null", input: "v: null", expected: nil},
+		{name: "tilde", input: "v: ~", expected: nil},
+		{name: "empty", input: "v:", expected: nil},
+		{name: "plain string", input: "v: hello world", expected: "hello world"},
+		{name: "comment", input: "v: hello # world", expected: "hello"},
+		{name: "hash in plain", input: "v: a#b", expected: "a#b"},
+		{name: "multi-line plain", input: "v: one\n  two\n\n  three", expected: "one two\nthree"},
+		{name: "single quoted", input: "v: 'it''s # here'", expected: "it's # here"},
+		{name: "double quoted", input: `v: "tab\tquote\" \u00e9 \U0001F600"`, expected: "tab\tquote\" \u00e9 \U0001F600"},
+		{name: "surrogate pair", input: `v: "\ud83d\ude00"`, expected: "\U0001F600"},
+		{name: "multi-line quoted", input: "v: \"one\n  two\"", expected: "one two"},
+		{name: "escaped line break", input: "v: \"one\\\n  two \\\n  three\"", expected: "onetwo three"},
+		{name: "escaped backslash at line end", input: "v: \"one\\\\\n  two\"", expected: "one\\ two"},
+		{name: "literal", input: "v: |\n  one\n   two\n\n", expected: "one\n two\n"},
+		{name: "literal strip", input: "v: |-\n  one\n  two\n", expected: "one\ntwo"},
+		{name: "literal keep", input: "v: |+\n  one\n\n", expected: "one\n\n"},
+		{name: "folded", input: "v: >\n  one\n  two\n\n  three\n", expected: "one two\nthree\n"},
+		{name: "indentation indicator", input: "v: |2\n   one\n  two\n", expected: " one\ntwo\n"},
+		{name: "str tag", input: "v: !!str 42", expected: "42"},
+		{name: "int tag", input: "v: !!int '42'", expected: 42},
+		{name: "float tag", input: "v: !!float 1", expected: 1.0},
+		{name: "non-specific tag", input: "v: ! 42", expected: "42"},
+	}
+
+	for i, test := range tests {
+		document, err := ParseYAML([]byte(test.input))
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(document["v"], test.expected) {
+			t.Errorf("\n[%d of %d: %s] Values should equal \n\t%#v \n \n\t%#v", i+1, len(tests), test.name, document["v"], test.expected)
+		}
+	}
+}
+
+func TestParseYAMLCollections(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			name:  "nested mappings",
+			input: "a:\n  b:\n    c: 1\n  d: 2\ne: 3\n",
+			expected: map[string]interface{}{
+				"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}, "d": 2},
+				"e": 3,
+			},
+		},
+		{
+			name:  "sequences",
+			input: "a:\n  - 1\n  - - 2\n    - 3\nb:\n- x\n- y\n",
+			expected: map[string]interface{}{
+				"a": []interface{}{1, []interface{}{2, 3}},
+				"b": []interface{}{"x", "y"},
+			},
+		},
+		{
+			name:  "compact mappings",
+			input: "a:\n  - name: x\n    value: 1\n  -   name: y\n",
+			expected: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"name": "x", "value": 1},
+					map[string]interface{}{"name": "y"},
+				},
+			},
+		},
+		{
+			name:  "flow collections",
+			input: "a: {b: [1, 'two', {c: null}], d: e f}\nx: [\n  1,\n  2,\n]\n",
+			expected: map[string]interface{}{
+				"a": map[string]interface{}{"b": []interface{}{1, "two", map[string]interface{}{"c": nil}}, "d": "e f"},
+				"x": []interface{}{1, 2},
+			},
+		},
+		{
+			name:  "anchors and aliases",
+			input: "base: &base\n  x: 1\ncopy: *base\nlist: [&one 1, *one]\n",
+			expected: map[string]interface{}{
+				"base": map[string]interface{}{"x": 1},
+				"copy": map[string]interface{}{"x": 1},
+				"list": []interface{}{1, 1},
+			},
+		},
+		{
+			name:  "quoted keys",
+			input: "\"a: b\": 1\n'c''d': 2\n3: three\n",
+			expected: map[string]interface{}{
+				"a: b": 1,
+				"c'd":  2,
+				"3":    "three",
+			},
+		},
+		{
+			name:     "directives and markers",
+			input:    "%YAML 1.2\n---\na: 1\n...\n# end\n",
+			expected: map[string]interface{}{"a": 1},
+		},
+		{
+			name:     "flow root",
+			input:    "{a: 1, b: [2]}\n",
+			expected: map[string]interface{}{"a": 1, "b": []interface{}{2}},
+		},
+		{
+			name:     "empty document",
+			input:    "# nothing\n",
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for i, test := range tests {
+		document, err := ParseYAML([]byte(test.input))
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(document, test.expected) {
+			t.Errorf("\n[%d of %d: %s] Documents should equal \n\t%#v \n \n\t%#v", i+1, len(tests), test.name, document, test.expected)
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{name: "not a mapping", input: "- a\n", err: fmt.Errorf("YAML document is not a mapping")},
+		{name: "duplicate key", input: "a: 1\na: 2\n", err: fmt.Errorf("Line 2: duplicate key a")},
+		{name: "indentation", input: "a: 1\n  b: 2\n", err: fmt.Errorf("Line 2: unexpected indentation")},
+		{name: "tabs", input: "a:\n\t- b\n", err: fmt.Errorf("Line 2: tabs are not allowed in indentation")},
+		{name: "unknown alias", input: "a: *x\n", err: fmt.Errorf("Line 1: unknown alias *x")},
+		{name: "several documents", input: "a: 1\n---\nb: 2\n", err: fmt.Errorf("Line 2: YAML streams with several documents are not supported")},
+		{name: "invalid int", input: "a: !!int x\n", err: fmt.Errorf(`Line 1: "x" is not a valid !!int`)},
+		{name: "unsupported tag", input: "a: !foo x\n", err: fmt.Errorf("Line 1: tag !foo is not supported")},
+		{name: "explicit key", input: "? a\n: b\n", err: fmt.Errorf("Line 1: explicit keys are not supported")},
+		{name: "unterminated flow", input: "a: [1, 2\n", err: fmt.Errorf("Line 1: unterminated flow collection")},
+	}
+
+	for i, test := range tests {
+		_, err := ParseYAML([]byte(test.input))
+		if fmt.Sprint(err) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, err, test.err)
+		}
+	}
+}
+
+func TestParseYAMLAliasExpansion(t *testing.T) {
+	laughs := "a: &a [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n"
+	for i, name := range []string{"b", "c", "d", "e", "f", "g", "h", "i"} {
+		previous := string(rune('a' + i))
+		laughs += fmt.Sprintf("%s: &%s [*%s, *%s, *%s, *%s, *%s, *%s, *%s, *%s, *%s]\n", name, name, previous, previous, previous, previous, previous, previous, previous, previous, previous)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{name: "flow", input: laughs, err: fmt.Errorf("Line 5: aliases expand to too many nodes")},
+		{name: "block", input: "a: &a [x, x, x, x, x, x, x, x, x, x]\nb: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]\nc: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]\nd: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]\ne:\n  - *d\n  - *d\n", err: fmt.Errorf("Line 6: aliases expand to too many nodes")},
+		{name: "within the budget", input: "a: &a [1, 2, 3]\nb: *a\nc: [*a,\n  *a]\n"},
+	}
+
+	for i, test := range tests {
+		_, err := ParseYAML([]byte(test.input))
+		if fmt.Sprint(err) != fmt.Sprint(test.err) {
+			t.Errorf("\n[%d of %d: %s] Errors should equal \n\t%v \n \n\t%v", i+1, len(tests), test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package gjm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const yamlSample = `%YAML 1.2
+---
+# Service configuration
+name: api   # the service name
+version: 3
+enabled: true
+
+server:
+  host: "0.0.0.0"
+  port: 8080
+  # TLS is optional
+  tls: {cert: /etc/cert.pem, key: /etc/key.pem}
+
+defaults: &defaults
+  retries: 3
+  timeout: 1.5
+worker:
+  <<: *defaults
+  queue: 'jobs'
+
+routes:
+  - path: /users
+    methods: [GET, POST]
+  - path: /health   # no auth
+    public: yes
+
+description: |
+  First line
+  second line
+notes: >-
+  folded
+  text
+empty:
+...
+# trailing comment
+`
+
+func TestYAMLDocumentRoundTrip(t *testing.T) {
+	inputs := []string{
+		yamlSample,
+		"a: 1\r\nb:\r\n  - x\r\n",
+		"{a: 1, b: [2, 3]}  # flow root\n",
+		"a:\n- 1\n-   b: 2\n    c: 3\n",
+		"key: plain text\n  continued\n\n  here\n",
+	}
+
+	for i, input := range inputs {
+		document, err := LoadYAML([]byte(input))
+		if err != nil {
+			t.Errorf("\n[%d of %d] Unexpected error %v", i+1, len(inputs), err)
+			continue
+		}
+		output, err := document.Encode()
+		if err != nil {
+			t.Errorf("\n[%d of %d] Unexpected error %v", i+1, len(inputs), err)
+			continue
+		}
+		if string(output) != input {
+			t.Errorf("\n[%d of %d] Output should equal \n\t%q \n \n\t%q", i+1, len(inputs), output, input)
+		}
+	}
+}
+
+func TestYAMLDocumentEdit(t *testing.T) {
+	input := "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\nlimits: {cpu: 1}\n"
+
+	tests := []struct {
+		name     string
+		change   func(data map[string]interface{}) error
+		expected string
+	}{
+		{
+			name:     "update keeps comments",
+			change:   func(data map[string]interface{}) error { return UpdateProperty(data, "name", "web") },
+			expected: "# settings\nname: web  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "nested update",
+			change:   func(data map[string]interface{}) error { return UpdateProperty(data, "server.port", 9090) },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 9090\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\nlimits: {cpu: 1}\n",
+		},
+		{
+			name: "create appends",
+			change: func(data map[string]interface{}) error {
+				return CreateProperty(data, "server.tls", map[string]interface{}{"on": true})
+			},
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\n  tls:\n    on: true\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "delete with comment",
+			change:   func(data map[string]interface{}) error { return DeleteProperty(data, "server.hosts") },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "append item",
+			change:   func(data map[string]interface{}) error { return UpdateProperty(data, "server.hosts[+]", "c") },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\n    - c\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "delete first item",
+			change:   func(data map[string]interface{}) error { return DeleteProperty(data, "server.hosts[0]") },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    - port: 1  # second\n      name: b\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "delete last item",
+			change:   func(data map[string]interface{}) error { return DeleteProperty(data, "server.hosts[1]") },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\nlimits: {cpu: 1}\n",
+		},
+		{
+			name:     "changed flow collection",
+			change:   func(data map[string]interface{}) error { return UpdateProperty(data, "limits.cpu", 2) },
+			expected: "# settings\nname: api  # comment\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\nlimits:\n  cpu: 2\n",
+		},
+		{
+			name:     "multi-line string",
+			change:   func(data map[string]interface{}) error { return UpdateProperty(data, "name", "one\ntwo") },
+			expected: "# settings\nname: |-  # comment\n  one\n  two\nserver:\n  port: 8080\n  # hosts\n  hosts:\n    # first\n    - a\n    - port: 1  # second\n      name: b\nlimits: {cpu: 1}\n",
+		},
+	}
+
+	for i, test := range tests {
+		document, err := LoadYAML([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.change(document.Data); err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		output, err := document.Encode()
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		if string(output) != test.expected {
+			t.Errorf("\n[%d of %d: %s] Output should equal \n\t%q \n \n\t%q", i+1, len(tests), test.name, output, test.expected)
+		}
+
+		decoded, err := ParseYAML(output)
+		if err != nil || !Equal(decoded, document.Data) {
+			t.Errorf("\n[%d of %d: %s] Output should decode to the document, got %v %v", i+1, len(tests), test.name, decoded, err)
+		}
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	ordered := NewOrderedMap()
+	ordered.Set("z", 1)
+	ordered.Set("a", []interface{}{})
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "sorted keys",
+			input:    map[string]interface{}{"b": 1, "a": "x"},
+			expected: "a: x\nb: 1\n",
+		},
+		{
+			name:     "ordered keys",
+			input:    ordered,
+			expected: "z: 1\na: []\n",
+		},
+		{
+			name: "nested",
+			input: map[string]interface{}{
+				"list": []interface{}{map[string]interface{}{"k": 1, "v": []interface{}{true, nil}}, "s"},
+				"map":  map[string]interface{}{},
+			},
+			expected: "list:\n  - k: 1\n    v:\n      - true\n      - null\n  - s\nmap: {}\n",
+		},
+		{
+			name: "quoted strings",
+			input: map[string]interface{}{
+				"a": "true",
+				"b": "12",
+				"c": "x: y",
+				"d": "",
+				"e": " padded",
+				"f": "#tag",
+				"g": "tab\there",
+			},
+			expected: "a: \"true\"\nb: \"12\"\nc: \"x: y\"\nd: \"\"\ne: \" padded\"\nf: \"#tag\"\ng: tab\there\n",
+		},
+		{
+			name:     "block strings",
+			input:    map[string]interface{}{"a": "one\ntwo\n", "b": "one\ntwo"},
+			expected: "a: |\n  one\n  two\nb: |-\n  one\n  two\n",
+		},
+		{
+			name:     "numbers",
+			input:    map[string]interface{}{"a": 1.5, "b": json.Number("12345678901234567890"), "c": uint8(7)},
+			expected: "a: 1.5\nb: 12345678901234567890\nc: 7\n",
+		},
+		{
+			name:     "integral floats",
+			input:    map[string]interface{}{"a": 1.0, "b": -3.0, "c": float32(2), "d": 1e21, "e": 0.0},
+			expected: "a: 1.0\nb: -3.0\nc: 2.0\nd: 1e+21\ne: 0.0\n",
+		},
+		{
+			name:     "structs",
+			input:    struct{ Name string }{Name: "x"},
+			expected: "Name: x\n",
+		},
+	}
+
+	for i, test := range tests {
+		output, err := MarshalYAML(test.input)
+		if err != nil {
+			t.Errorf("\n[%d of %d: %s] Unexpected error %v", i+1, len(tests), test.name, err)
+			continue
+		}
+		if string(output) != test.expected {
+			t.Errorf("\n[%d of %d: %s] Output should equal \n\t%q \n \n\t%q", i+1, len(tests), test.name, output, test.expected)
+		}
+	}
+}
+
+func TestMarshalYAMLFloatRoundTrip(t *testing.T) {
+	input := map[string]interface{}{
+		"one":      1.0,
+		"negative": -42.0,
+		"zero":     0.0,
+		"fraction": 0.25,
+		"large":    1e21,
+		"small":    1e-7,
+		"nested":   map[string]interface{}{"list": []interface{}{2.0, 3}},
+	}
+
+	output, err := MarshalYAML(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ParseYAML(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, input) {
+		t.Errorf("Decoded output should equal \n\t%#v \n \n\t%#v", decoded, input)
+	}
+}